@@ -0,0 +1,276 @@
+package main
+
+import (
+    "container/list"
+    "crypto/sha1"
+    "encoding/hex"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+)
+
+// ---------- Preview cache ----------
+
+// statKey identifies one version of a file; any change invalidates the
+// cached preview. The ctime catches chmod, chown and xattr changes, which
+// leave mtime and size alone (it is zero where unknown, e.g. Windows).
+type statKey struct {
+    MTime int64  `json:"mtime"`
+    CTime int64  `json:"ctime"`
+    Size  int64  `json:"size"`
+    Ino   uint64 `json:"ino"`
+}
+
+func statKeyOf(p string) (statKey, bool) {
+    fi, err := os.Lstat(p)
+    if err != nil { return statKey{}, false }
+    k := statKey{MTime: fi.ModTime().UnixNano(), Size: fi.Size(), Ino: fileIno(fi)}
+    if ct := inspect.StatOf(fi).CTime; !ct.IsZero() { k.CTime = ct.UnixNano() }
+    return k, true
+}
+
+type cacheEntry struct {
+    id  string // path + long/brief flag
+    key statKey
+    doc *inspect.Document
+    raw string
+}
+
+// diskEntry is the on-disk form of a cache entry.
+type diskEntry struct {
+    Path string          `json:"path"`
+    Long bool            `json:"long"`
+    Key  statKey         `json:"key"`
+    Doc  json.RawMessage `json:"doc"`
+}
+
+// previewCache is a bounded LRU of inspected documents, shared by the
+// preview loader and the prefetchers (hence the mutex). When dir is set,
+// entries are also persisted as JSON files so a restart starts warm.
+type previewCache struct {
+    mu         sync.Mutex
+    cap        int
+    ll         *list.List
+    items      map[string]*list.Element
+    dir        string
+    hits       int
+    misses     int
+    diskHits   int
+    prefetched int
+    saves      int
+    pruning    sync.Mutex // held while a disk prune runs
+}
+
+// The disk cache is pruned at startup and every diskPruneEvery writes: stale
+// entries go first, then the least recently used until it fits both limits.
+const (
+    diskCacheMaxEntries = 4096
+    diskCacheMaxBytes   = 64 << 20
+    diskPruneEvery      = 256
+)
+
+func newPreviewCache(capacity int, dir string) *previewCache {
+    if capacity <= 0 { capacity = 512 }
+    if dir != "" {
+        if err := os.MkdirAll(dir, 0o700); err != nil { dir = "" }
+    }
+    c := &previewCache{cap: capacity, ll: list.New(), items: make(map[string]*list.Element, capacity), dir: dir}
+    if dir != "" { go c.pruneDisk(diskCacheMaxEntries, diskCacheMaxBytes) }
+    return c
+}
+
+// previewCacheFromEnv reads FINFOTUI_CACHE_SIZE and FINFOTUI_DISK_CACHE.
+func previewCacheFromEnv() *previewCache {
+    size := 512
+    if v := os.Getenv("FINFOTUI_CACHE_SIZE"); v != "" {
+        if n, err := strconv.Atoi(v); err == nil && n > 0 { size = n }
+    }
+    dir := ""
+    if v := os.Getenv("FINFOTUI_DISK_CACHE"); v == "1" || v == "true" || v == "yes" {
+        if base, err := os.UserCacheDir(); err == nil { dir = filepath.Join(base, "finfo", "tui", "previews") }
+    }
+    return newPreviewCache(size, dir)
+}
+
+func cacheID(p string, long bool) string {
+    if long { return "L\x00" + p }
+    return "B\x00" + p
+}
+
+// get returns the cached document for p if the file has not changed.
+func (c *previewCache) get(p string, long bool) (*inspect.Document, string, bool) {
+    if c == nil { return nil, "", false }
+    key, ok := statKeyOf(p)
+    id := cacheID(p, long)
+    c.mu.Lock()
+    if el, found := c.items[id]; found {
+        e := el.Value.(*cacheEntry)
+        if ok && e.key == key {
+            c.ll.MoveToFront(el)
+            c.hits++
+            c.mu.Unlock()
+            return e.doc, e.raw, true
+        }
+        c.ll.Remove(el)
+        delete(c.items, id)
+    }
+    c.mu.Unlock()
+    if ok {
+        if doc, raw, found := c.loadDisk(p, long, key); found {
+            c.mu.Lock(); c.diskHits++; c.insert(&cacheEntry{id: id, key: key, doc: doc, raw: raw}); c.mu.Unlock()
+            return doc, raw, true
+        }
+    }
+    c.mu.Lock(); c.misses++; c.mu.Unlock()
+    return nil, "", false
+}
+
+// contains reports whether a valid entry is in memory, without touching the
+// hit/miss counters or the LRU order (used by prefetching).
+func (c *previewCache) contains(p string, long bool) bool {
+    if c == nil { return false }
+    key, ok := statKeyOf(p)
+    if !ok { return false }
+    c.mu.Lock(); defer c.mu.Unlock()
+    el, found := c.items[cacheID(p, long)]
+    return found && el.Value.(*cacheEntry).key == key
+}
+
+// put stores a freshly inspected document keyed by the file's current stat.
+func (c *previewCache) put(p string, long bool, doc *inspect.Document, raw string) {
+    if c == nil || doc == nil { return }
+    key, ok := statKeyOf(p)
+    if !ok { return }
+    c.mu.Lock()
+    c.insert(&cacheEntry{id: cacheID(p, long), key: key, doc: doc, raw: raw})
+    c.mu.Unlock()
+    c.saveDisk(p, long, key, raw)
+}
+
+func (c *previewCache) insert(e *cacheEntry) {
+    if el, found := c.items[e.id]; found {
+        el.Value = e
+        c.ll.MoveToFront(el)
+        return
+    }
+    c.items[e.id] = c.ll.PushFront(e)
+    for c.ll.Len() > c.cap {
+        old := c.ll.Back()
+        c.ll.Remove(old)
+        delete(c.items, old.Value.(*cacheEntry).id)
+    }
+}
+
+func (c *previewCache) notePrefetch() {
+    c.mu.Lock(); c.prefetched++; c.mu.Unlock()
+}
+
+// invalidate drops entries for paths (both modes), in memory and on disk.
+func (c *previewCache) invalidate(paths []string) {
+    if c == nil { return }
+    c.mu.Lock()
+    for _, p := range paths {
+        for _, long := range []bool{false, true} {
+            id := cacheID(p, long)
+            if el, found := c.items[id]; found { c.ll.Remove(el); delete(c.items, id) }
+        }
+    }
+    c.mu.Unlock()
+    if c.dir == "" { return }
+    for _, p := range paths {
+        _ = os.Remove(c.diskPath(p, false))
+        _ = os.Remove(c.diskPath(p, true))
+    }
+}
+
+// purge empties the in-memory cache (used by Refresh).
+func (c *previewCache) purge() {
+    if c == nil { return }
+    c.mu.Lock()
+    c.ll.Init()
+    c.items = make(map[string]*list.Element, c.cap)
+    c.mu.Unlock()
+}
+
+type cacheStats struct{ size, cap, hits, misses, diskHits, prefetched int; disk bool }
+
+func (c *previewCache) stats() cacheStats {
+    if c == nil { return cacheStats{} }
+    c.mu.Lock(); defer c.mu.Unlock()
+    return cacheStats{size: c.ll.Len(), cap: c.cap, hits: c.hits, misses: c.misses, diskHits: c.diskHits, prefetched: c.prefetched, disk: c.dir != ""}
+}
+
+func (c *previewCache) diskPath(p string, long bool) string {
+    sum := sha1.Sum([]byte(cacheID(p, long)))
+    return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *previewCache) loadDisk(p string, long bool, key statKey) (*inspect.Document, string, bool) {
+    if c.dir == "" { return nil, "", false }
+    file := c.diskPath(p, long)
+    b, err := os.ReadFile(file)
+    if err != nil { return nil, "", false }
+    var de diskEntry
+    if err := json.Unmarshal(b, &de); err != nil || de.Path != p || de.Long != long || de.Key != key { os.Remove(file); return nil, "", false }
+    doc, err := inspect.Decode(de.Doc, inspect.Lenient)
+    if err != nil { os.Remove(file); return nil, "", false }
+    now := time.Now()
+    _ = os.Chtimes(file, now, now) // the mtime orders eviction
+    return doc, string(de.Doc), true
+}
+
+func (c *previewCache) saveDisk(p string, long bool, key statKey, raw string) {
+    if c.dir == "" || raw == "" { return }
+    b, err := json.Marshal(diskEntry{Path: p, Long: long, Key: key, Doc: json.RawMessage(raw)})
+    if err != nil { return }
+    _ = writeFileAtomic(c.diskPath(p, long), b)
+    c.mu.Lock()
+    c.saves++
+    prune := c.saves%diskPruneEvery == 0
+    c.mu.Unlock()
+    if prune { go c.pruneDisk(diskCacheMaxEntries, diskCacheMaxBytes) }
+}
+
+// pruneDisk removes entries whose file changed or is gone, unreadable
+// entries and leftover temp files, then evicts the least recently used
+// entries until at most maxEntries and maxBytes remain.
+func (c *previewCache) pruneDisk(maxEntries int, maxBytes int64) {
+    if !c.pruning.TryLock() { return }
+    defer c.pruning.Unlock()
+    des, err := os.ReadDir(c.dir)
+    if err != nil { return }
+    type kept struct {
+        path  string
+        size  int64
+        mtime time.Time
+    }
+    var keep []kept
+    var total int64
+    for _, d := range des {
+        file := filepath.Join(c.dir, d.Name())
+        fi, err := d.Info()
+        if err != nil || d.IsDir() { continue }
+        if !strings.HasSuffix(d.Name(), ".json") {
+            if strings.HasPrefix(d.Name(), ".tmp-") && time.Since(fi.ModTime()) > time.Hour { os.Remove(file) }
+            continue
+        }
+        b, err := os.ReadFile(file)
+        if err != nil { continue }
+        var de diskEntry
+        if json.Unmarshal(b, &de) != nil { os.Remove(file); continue }
+        if key, ok := statKeyOf(de.Path); !ok || key != de.Key { os.Remove(file); continue }
+        keep = append(keep, kept{file, fi.Size(), fi.ModTime()})
+        total += fi.Size()
+    }
+    sort.Slice(keep, func(i, j int) bool { return keep[i].mtime.Before(keep[j].mtime) })
+    for i := 0; i < len(keep) && (len(keep)-i > maxEntries || total > maxBytes); i++ {
+        if os.Remove(keep[i].path) == nil { total -= keep[i].size }
+    }
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+)
+
+func TestPruneDisk(t *testing.T) {
+    dir, files := t.TempDir(), t.TempDir()
+    c := &previewCache{dir: dir}
+    raw := `{"name":"x"}`
+    var paths []string
+    for i, name := range []string{"a", "b", "c", "d"} {
+        p := filepath.Join(files, name)
+        if err := os.WriteFile(p, []byte(name), 0o644); err != nil { t.Fatal(err) }
+        key, _ := statKeyOf(p)
+        c.saveDisk(p, false, key, raw)
+        old := time.Now().Add(time.Duration(i-10) * time.Minute) // a is the least recently used
+        os.Chtimes(c.diskPath(p, false), old, old)
+        paths = append(paths, p)
+    }
+    // b changes and c disappears: both are stale
+    os.WriteFile(paths[1], []byte("changed"), 0o644)
+    os.Remove(paths[2])
+    os.WriteFile(filepath.Join(dir, "junk.json"), []byte("{"), 0o600)
+
+    c.pruneDisk(1, 1<<20)
+    des, _ := os.ReadDir(dir)
+    if len(des) != 1 || des[0].Name() != filepath.Base(c.diskPath(paths[3], false)) {
+        var names []string
+        for _, d := range des { names = append(names, d.Name()) }
+        t.Fatalf("left %v, want only the entry for d", names)
+    }
+
+    // a lookup for a changed file removes its entry
+    os.WriteFile(paths[3], []byte("changed too"), 0o644)
+    key, _ := statKeyOf(paths[3])
+    if _, _, ok := c.loadDisk(paths[3], false, key); ok { t.Fatal("stale entry loaded") }
+    if _, err := os.Stat(c.diskPath(paths[3], false)); !os.IsNotExist(err) { t.Errorf("stale entry kept on disk") }
+}
+
+func TestPruneDiskBytes(t *testing.T) {
+    dir, files := t.TempDir(), t.TempDir()
+    c := &previewCache{dir: dir}
+    var last string
+    for i := 0; i < 5; i++ {
+        p := filepath.Join(files, string(rune('a'+i)))
+        os.WriteFile(p, []byte("x"), 0o644)
+        key, _ := statKeyOf(p)
+        c.saveDisk(p, true, key, `{"name":"x"}`)
+        old := time.Now().Add(time.Duration(i-10) * time.Minute)
+        os.Chtimes(c.diskPath(p, true), old, old)
+        last = c.diskPath(p, true)
+    }
+    fi, _ := os.Stat(last)
+    c.pruneDisk(100, 2*fi.Size())
+    des, _ := os.ReadDir(dir)
+    if len(des) != 2 { t.Fatalf("%d entries left, want 2", len(des)) }
+    if _, err := os.Stat(last); err != nil { t.Errorf("newest entry evicted") }
+}
+
+func TestCacheMissAfterChmod(t *testing.T) {
+    p := filepath.Join(t.TempDir(), "f")
+    os.WriteFile(p, []byte("x"), 0o644)
+    c := newPreviewCache(8, "")
+    c.put(p, true, &inspect.Document{Name: "f"}, `{"name":"f"}`)
+    if _, _, ok := c.get(p, true); !ok { t.Fatal("fresh entry missed") }
+    time.Sleep(20 * time.Millisecond) // past the filesystem's timestamp granularity
+    if err := os.Chmod(p, 0o600); err != nil { t.Fatal(err) }
+    if k, _ := statKeyOf(p); k.CTime == 0 { t.Skip("no ctime on this platform") }
+    if _, _, ok := c.get(p, true); ok { t.Error("entry still served after chmod") }
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "os/exec"
+    "os/user"
+    "path/filepath"
+    "runtime"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Ownership ----------
+
+// ownerChange is what the undo journal keeps for a chown: the ids before
+// and after (-1 after means that id was left alone).
+type ownerChange struct {
+    UID    int `json:"uid"`
+    GID    int `json:"gid"`
+    NewUID int `json:"new_uid"`
+    NewGID int `json:"new_gid"`
+}
+
+// chownPlan is a parsed "user:group" with the targets it applies to.
+type chownPlan struct {
+    spec      string
+    uid, gid  int // -1 leaves it alone
+    recursive bool
+}
+
+// localNames lists the names in /etc/passwd (or /etc/group), plus on macOS
+// the ones Directory Services knows.
+func localNames(groups bool) []string {
+    file, dscl := "/etc/passwd", "/Users"
+    if groups { file, dscl = "/etc/group", "/Groups" }
+    seen := map[string]bool{}
+    if f, err := os.Open(file); err == nil {
+        sc := bufio.NewScanner(f)
+        for sc.Scan() {
+            line := sc.Text()
+            if line == "" || line[0] == '#' { continue }
+            if name, _, ok := strings.Cut(line, ":"); ok { seen[name] = true }
+        }
+        f.Close()
+    }
+    if runtime.GOOS == "darwin" {
+        if out, err := exec.Command("dscl", ".", "-list", dscl).Output(); err == nil {
+            for _, name := range strings.Fields(string(out)) { seen[name] = true }
+        }
+    }
+    names := make([]string, 0, len(seen))
+    for name := range seen {
+        if !strings.HasPrefix(name, "_") || runtime.GOOS != "darwin" { names = append(names, name) } // skip macOS service accounts
+    }
+    sort.Strings(names)
+    return names
+}
+
+// chownSuggestions completes the user part, or the group part after a colon.
+func chownSuggestions(value string, users, groups []string) []string {
+    var out []string
+    if u, _, ok := strings.Cut(value, ":"); ok {
+        for _, g := range groups { out = append(out, u+":"+g) }
+        return out
+    }
+    return users
+}
+
+// parseChown reads user, user:group, :group or user: (the user's login
+// group); names or numeric ids.
+func parseChown(s string) (*chownPlan, error) {
+    s = strings.TrimSpace(s)
+    p := &chownPlan{spec: s, uid: -1, gid: -1}
+    u, g, colon := strings.Cut(s, ":")
+    if u == "" && g == "" { return nil, errors.New("expected user, user:group or :group") }
+    if u != "" {
+        if id, err := strconv.Atoi(u); err == nil && id >= 0 {
+            p.uid = id
+            if colon && g == "" {
+                // like chown(1), "1000:" also takes uid 1000's login group
+                usr, err := user.LookupId(u)
+                if err != nil { return nil, fmt.Errorf("no user with id %d to take the login group from", id) }
+                p.gid, _ = strconv.Atoi(usr.Gid)
+            }
+        } else {
+            usr, err := user.Lookup(u)
+            if err != nil { return nil, fmt.Errorf("no user %q", u) }
+            p.uid, _ = strconv.Atoi(usr.Uid)
+            if colon && g == "" { p.gid, _ = strconv.Atoi(usr.Gid) }
+        }
+    }
+    if g != "" {
+        if id, err := strconv.Atoi(g); err == nil && id >= 0 {
+            p.gid = id
+        } else {
+            grp, err := user.LookupGroup(g)
+            if err != nil { return nil, fmt.Errorf("no group %q", g) }
+            p.gid, _ = strconv.Atoi(grp.Gid)
+        }
+    }
+    return p, nil
+}
+
+// ownerOf is "user:group" for a path.
+func ownerOf(fi fs.FileInfo) (uid, gid int, names string) {
+    st := inspect.StatOf(fi)
+    un, gn := inspect.OwnerNames(st.UID, st.GID)
+    return int(st.UID), int(st.GID), un + ":" + gn
+}
+
+// ---------- Model glue ----------
+
+// openChown asks for the new owner, completing names as it is typed.
+func (m *model) openChown() {
+    m.mode = modeChown
+    m.filter.Placeholder = "user, user:group or :group (tab completes)"
+    m.filter.SetValue("")
+    m.ownerNames = [2][]string{localNames(false), localNames(true)}
+    m.filter.ShowSuggestions = true
+    m.filter.SetSuggestions(m.ownerNames[0])
+    m.filter.Focus()
+}
+
+// closeChownInput stops completing in the shared text input.
+func (m *model) closeChownInput() {
+    m.filter.ShowSuggestions = false
+    m.filter.SetSuggestions(nil)
+    m.filter.Blur()
+}
+
+// planChown shows the dry-run for the targets in the ops overlay.
+func (m *model) planChown(p *chownPlan) {
+    if m.chown != nil { p.recursive = m.chown.recursive }
+    m.chown = p
+    targets := m.targetItems()
+    ops := make([]op, len(targets))
+    for i, t := range targets { ops[i] = op{from: t.path} }
+    m.pendingOps = ops
+    m.pendingAct = actChown
+    m.opsOverlayText = m.chownPlanText()
+    m.opsOverlay.SetContent(m.opsOverlayText)
+    m.mode = modeOpsPreview
+    m.status = "enter to confirm, esc to cancel"
+}
+
+func (m *model) chownPlanText() string {
+    p := m.chown
+    b := &strings.Builder{}
+    rec := "off"
+    if p.recursive { rec = "on" }
+    fmt.Fprintf(b, "Change owner → %s   (R recursive: %s)\n\n", p.spec, rec)
+    needRoot := false
+    for _, o := range m.pendingOps {
+        fi, err := os.Stat(o.from)
+        if err != nil { fmt.Fprintf(b, "  %s: %v\n", o.from, err); continue }
+        uid, gid, from := ownerOf(fi)
+        nu, ng := uid, gid
+        if p.uid >= 0 { nu = p.uid }
+        if p.gid >= 0 { ng = p.gid }
+        un, gn := inspect.OwnerNames(uint32(nu), uint32(ng))
+        name := filepath.Base(o.from)
+        if fi.IsDir() {
+            name += "/"
+            if p.recursive { name += " (and its contents)" }
+        }
+        arrow := "→"
+        if nu == uid && ng == gid { arrow = "=" }
+        fmt.Fprintf(b, "  %-20s %s %-20s %s\n", from, arrow, un+":"+gn, name)
+        if nu != uid && os.Geteuid() != 0 { needRoot = true }
+    }
+    if needRoot { b.WriteString("\n  changing the owner needs root; this will likely be refused\n") }
+    b.WriteString("\nenter applies · R recursive · e edit · esc cancel")
+    return b.String()
+}
+
+// chownPreviewKey handles R and e in the dry-run; it reports whether it
+// used the key.
+func (m *model) chownPreviewKey(k string) bool {
+    switch k {
+    case "R":
+        m.chown.recursive = !m.chown.recursive
+        m.opsOverlayText = m.chownPlanText()
+        m.opsOverlay.SetContent(m.opsOverlayText)
+    case "e":
+        m.openChown()
+        m.filter.SetValue(m.chown.spec)
+        m.filter.CursorEnd()
+        m.filter.SetSuggestions(chownSuggestions(m.chown.spec, m.ownerNames[0], m.ownerNames[1]))
+        m.pendingOps = nil
+    default: return false
+    }
+    return true
+}
+
+// startChown runs the plan as a job.
+func (m *model) startChown() tea.Cmd {
+    p, ops := m.chown, m.pendingOps
+    m.pendingOps = nil; m.pendingAct = 0; m.chown = nil
+    paths := make([]string, len(ops))
+    for i, o := range ops { paths[i] = o.from }
+    return m.startJob("chown", targetLabel(paths), chownJob(m.journal.newBatch(), paths, *p))
+}
+
+// chownJob changes ownership, carrying on past entries it cannot change.
+// Symlinks given as targets are followed; inside a recursive walk they are
+// changed themselves, like chown -R.
+func chownJob(batch int64, paths []string, p chownPlan) jobFunc {
+    return func(ctx context.Context, j *job) (tea.Msg, error) {
+        j.total.Store(int64(len(paths)))
+        msg := batchDoneMsg{act: actChown, batch: batch}
+        failed, tried := 0, 0
+        var first error
+        fail := func(err error) {
+            failed++
+            if first == nil { first = err }
+            j.notes = append(j.notes, err.Error())
+        }
+        chown := func(path string, fi fs.FileInfo) {
+            uid, gid, _ := ownerOf(fi)
+            nu, ng := uid, gid
+            if p.uid >= 0 { nu = p.uid }
+            if p.gid >= 0 { ng = p.gid }
+            if nu == uid && ng == gid { return }
+            tried++
+            if err := os.Lchown(path, p.uid, p.gid); err != nil {
+                if errors.Is(err, fs.ErrPermission) {
+                    err = fmt.Errorf("%s: not permitted (only root can give files away; the group must be one you belong to)", path)
+                }
+                fail(err)
+                return
+            }
+            msg.recs = append(msg.recs, journalOp{Kind: "chown", From: path, Owner: &ownerChange{UID: uid, GID: gid, NewUID: p.uid, NewGID: p.gid}})
+            msg.n++
+        }
+        for _, path := range paths {
+            if err := ctx.Err(); err != nil { return msg, err }
+            if real, err := filepath.EvalSymlinks(path); err == nil { path = real }
+            fi, err := os.Lstat(path)
+            if err != nil { tried++; fail(err); continue }
+            chown(path, fi)
+            if p.recursive && fi.IsDir() {
+                filepath.WalkDir(path, func(q string, d fs.DirEntry, err error) error {
+                    if ctx.Err() != nil { return ctx.Err() }
+                    if err != nil { fail(err); return nil }
+                    if q == path { return nil }
+                    if info, err := d.Info(); err == nil { chown(q, info) }
+                    return nil
+                })
+            }
+            j.n.Add(1)
+        }
+        if err := ctx.Err(); err != nil { return msg, err }
+        if failed > 0 && tried > 1 { return msg, fmt.Errorf("%d of %d failed: %w", failed, tried, first) }
+        return msg, first
+    }
+}
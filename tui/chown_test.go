@@ -0,0 +1,53 @@
+package main
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+)
+
+func TestParseChown(t *testing.T) {
+    tests := []struct {
+        spec     string
+        uid, gid int
+    }{
+        {"0", 0, -1},
+        {"0:", 0, 0}, // login group of uid 0
+        {":0", -1, 0},
+        {"0:0", 0, 0},
+        {" root ", 0, -1},
+        {"root:", 0, 0},
+        {"root:0", 0, 0},
+    }
+    for _, tt := range tests {
+        p, err := parseChown(tt.spec)
+        if err != nil { t.Errorf("parseChown(%q): %v", tt.spec, err); continue }
+        if p.uid != tt.uid || p.gid != tt.gid { t.Errorf("parseChown(%q) = %d:%d, want %d:%d", tt.spec, p.uid, p.gid, tt.uid, tt.gid) }
+    }
+}
+
+func TestParseChownErrors(t *testing.T) {
+    for _, s := range []string{"", ":", "no-such-user-finfo", "no-such-user-finfo:", "0:no-such-group-finfo", "2147480000:"} {
+        if _, err := parseChown(s); err == nil { t.Errorf("parseChown(%q) accepted", s) }
+    }
+}
+
+func TestChownJobRefreshesPreview(t *testing.T) {
+    if os.Geteuid() != 0 { t.Skip("giving a file away needs root") }
+    dir := t.TempDir()
+    f := filepath.Join(dir, "f")
+    os.WriteFile(f, nil, 0o644)
+    m := undoTestModel(t, dir)
+    if msg := runPreview(context.Background(), m.cache, f, false, ""); msg.doc == nil { t.Fatalf("preview before chown: %+v", msg) }
+
+    p, err := parseChown("1:1")
+    if err != nil { t.Fatal(err) }
+    m = runJob(t, m, m.startJob("chown", f, chownJob(m.journal.newBatch(), []string{f}, *p)))
+    if _, found := m.cache.items[cacheID(f, false)]; found { t.Error("cached preview survived the chown") }
+    msg := runPreview(context.Background(), m.cache, f, false, "")
+    want, _ := inspect.OwnerNames(1, 1)
+    if msg.doc == nil || msg.doc.Owner.User != want { t.Errorf("preview after chown: %+v, want owner %s", msg.doc, want) }
+}
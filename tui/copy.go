@@ -0,0 +1,339 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "sync/atomic"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Copying ----------
+
+// conflictPolicy decides what a copy does when the destination exists.
+// Directories that exist on both sides are merged under every policy but
+// rename.
+type conflictPolicy int
+
+const (
+    conflictRename    conflictPolicy = iota // "name (n).ext", like moves
+    conflictSkip                            // keep the existing entry
+    conflictOverwrite                       // replace it
+    conflictNewer                           // replace it when the source is newer
+    numConflictPolicies
+)
+
+var conflictNames = [numConflictPolicies]string{"rename (n)", "skip", "overwrite", "newer wins"}
+
+type copyOptions struct {
+    policy conflictPolicy
+    verify bool // compare SHA-256 checksums after each file
+}
+
+// copyProgress is shared between a running copy and the status line, which
+// reads it on every spinner tick.
+type copyProgress struct {
+    total     atomic.Int64 // bytes of regular files to copy
+    done      atomic.Int64
+    fileSize  atomic.Int64
+    fileDone  atomic.Int64
+    verifying atomic.Bool
+    mu        sync.Mutex
+    name      string // file being copied
+}
+
+func (p *copyProgress) start(name string, size int64) {
+    p.mu.Lock()
+    p.name = name
+    p.mu.Unlock()
+    p.fileSize.Store(size)
+    p.fileDone.Store(0)
+}
+
+func (p *copyProgress) add(n int64) {
+    p.done.Add(n)
+    p.fileDone.Add(n)
+}
+
+// String is the status-line part: total then current file progress.
+func (p *copyProgress) String() string {
+    total, done := p.total.Load(), p.done.Load()
+    pct := 100
+    if total > 0 { pct = int(done * 100 / total) }
+    p.mu.Lock()
+    name := p.name
+    p.mu.Unlock()
+    s := fmt.Sprintf("copy %d%% %s/%s", pct, sizeFmt(done, unitScheme()), sizeFmt(total, unitScheme()))
+    if name == "" { return s }
+    if p.verifying.Load() { return s + " · verifying " + name }
+    if size := p.fileSize.Load(); size > 0 { return fmt.Sprintf("%s · %s %d%%", s, name, p.fileDone.Load()*100/size) }
+    return s + " · " + name
+}
+
+// countingWriter feeds the progress and stops the copy once ctx is done.
+type countingWriter struct {
+    ctx context.Context
+    w   io.Writer
+    p   *copyProgress
+}
+
+func (c countingWriter) Write(b []byte) (int, error) {
+    if err := c.ctx.Err(); err != nil { return 0, err }
+    n, err := c.w.Write(b)
+    c.p.add(int64(n))
+    return n, err
+}
+
+// copier copies entries under one set of options, counting what it skips
+// and journalling the top-level copies.
+type copier struct {
+    ctx     context.Context
+    opt     copyOptions
+    p       *copyProgress
+    skipped int
+    wrote   int // entries created or replaced
+    done    []journalOp
+}
+
+// copyTotal sums the regular-file bytes under paths; symlinks are not
+// followed.
+func copyTotal(paths []string) int64 {
+    var n int64
+    for _, p := range paths {
+        filepath.WalkDir(p, func(_ string, d fs.DirEntry, err error) error {
+            if err != nil || !d.Type().IsRegular() { return nil }
+            if fi, err := d.Info(); err == nil { n += fi.Size() }
+            return nil
+        })
+    }
+    return n
+}
+
+// copyTop copies from to to, picking a "(n)" name first under the rename
+// policy. A new entry that fails half-way is removed again; an existing one
+// the policy kept as it was is not journalled.
+func (c *copier) copyTop(from, to string) error {
+    af, err1 := filepath.Abs(from)
+    at, err2 := filepath.Abs(to)
+    if err1 == nil && err2 == nil && strings.HasPrefix(at, af+string(filepath.Separator)) {
+        return fmt.Errorf("%s: cannot copy a directory into itself", from)
+    }
+    _, err := os.Lstat(to)
+    if err == nil && c.opt.policy == conflictRename {
+        to, err = uniqueDest(filepath.Dir(to), filepath.Base(to)), os.ErrNotExist
+    }
+    fresh, wrote := err != nil, c.wrote
+    if err := c.copy(from, to); err != nil {
+        if fresh { os.RemoveAll(to) }
+        return err
+    }
+    if c.wrote == wrote { return nil }
+    c.done = append(c.done, journalOp{Kind: "copy", From: from, To: to, Replaced: !fresh})
+    return nil
+}
+
+func (c *copier) copy(from, to string) error {
+    if err := c.ctx.Err(); err != nil { return err }
+    fi, err := os.Lstat(from)
+    if err != nil { return err }
+    if dfi, err := os.Lstat(to); err == nil {
+        switch {
+        case os.SameFile(fi, dfi): return fmt.Errorf("%s: source and destination are the same", from)
+        case fi.IsDir() && dfi.IsDir(): return c.copyDir(from, to, fi, true)
+        case fi.IsDir() || dfi.IsDir(): return fmt.Errorf("%s: cannot replace %s with %s", to, dfi.Mode().Type(), fi.Mode().Type())
+        case c.opt.policy == conflictSkip, c.opt.policy == conflictNewer && !fi.ModTime().After(dfi.ModTime()):
+            c.skipped++
+            if fi.Mode().IsRegular() { c.p.done.Add(fi.Size()) }
+            return nil
+        }
+    }
+    switch {
+    case fi.Mode()&os.ModeSymlink != 0:
+        target, err := os.Readlink(from)
+        if err != nil { return err }
+        os.Remove(to) // only there when overwriting
+        if err := os.Symlink(target, to); err != nil { return err }
+        c.wrote++
+        return nil
+    case fi.IsDir():
+        return c.copyDir(from, to, fi, false)
+    case !fi.Mode().IsRegular():
+        return fmt.Errorf("%s: cannot copy %s", from, fi.Mode().Type())
+    }
+    return c.copyFile(from, to, fi)
+}
+
+// copyDir copies the entries of from into to, creating it unless merging.
+// A merged directory keeps its own mode and times.
+func (c *copier) copyDir(from, to string, fi fs.FileInfo, merge bool) error {
+    if !merge {
+        if err := os.Mkdir(to, fi.Mode().Perm()|0700); err != nil { return err }
+        c.wrote++
+    }
+    entries, err := os.ReadDir(from)
+    if err != nil { return err }
+    for _, e := range entries {
+        if err := c.copy(filepath.Join(from, e.Name()), filepath.Join(to, e.Name())); err != nil { return err }
+    }
+    if merge { return nil }
+    copyXattrs(from, to)
+    if err := os.Chmod(to, fi.Mode().Perm()); err != nil { return err }
+    return keepTimes(to, fi)
+}
+
+// copyFile writes a temporary file next to to and renames it into place, so
+// an interrupted copy never leaves a partial file under the final name.
+func (c *copier) copyFile(from, to string, fi fs.FileInfo) error {
+    c.p.start(filepath.Base(from), fi.Size())
+    in, err := os.Open(from)
+    if err != nil { return err }
+    defer in.Close()
+    out, err := os.CreateTemp(filepath.Dir(to), "."+filepath.Base(to)+".finfo-*")
+    if err != nil { return err }
+    tmp := out.Name()
+    fail := func(err error) error { out.Close(); os.Remove(tmp); return err }
+    if _, err := io.Copy(countingWriter{c.ctx, out, c.p}, in); err != nil { return fail(err) }
+    if err := out.Chmod(fi.Mode().Perm()); err != nil { return fail(err) }
+    if err := out.Close(); err != nil { return fail(err) }
+    copyXattrs(from, tmp)
+    if err := keepTimes(tmp, fi); err != nil { os.Remove(tmp); return err }
+    if c.opt.verify {
+        c.p.verifying.Store(true)
+        err := verifyCopy(from, tmp)
+        c.p.verifying.Store(false)
+        if err != nil { os.Remove(tmp); return err }
+    }
+    if err := os.Rename(tmp, to); err != nil { os.Remove(tmp); return err }
+    c.wrote++
+    return nil
+}
+
+// keepTimes gives to the access and modification times of fi.
+func keepTimes(to string, fi fs.FileInfo) error {
+    atime := inspect.StatOf(fi).ATime
+    if atime.IsZero() { atime = fi.ModTime() }
+    return os.Chtimes(to, atime, fi.ModTime())
+}
+
+func fileSum(p string) ([]byte, error) {
+    f, err := os.Open(p)
+    if err != nil { return nil, err }
+    defer f.Close()
+    h := sha256.New()
+    if _, err := io.Copy(h, f); err != nil { return nil, err }
+    return h.Sum(nil), nil
+}
+
+func verifyCopy(a, b string) error {
+    sa, err := fileSum(a)
+    if err != nil { return err }
+    sb, err := fileSum(b)
+    if err != nil { return err }
+    if !bytes.Equal(sa, sb) { return fmt.Errorf("%s: checksum mismatch after copy", a) }
+    return nil
+}
+
+// ---------- Model glue ----------
+
+type copyDoneMsg struct {
+    batch   int64
+    done    []journalOp
+    n       int
+    skipped int
+    verify  bool
+}
+
+// startCopy runs the ops as one job whose byte progress shows in the status
+// line.
+func (m *model) startCopy(ops []op) tea.Cmd {
+    from := make([]string, len(ops))
+    for i, op := range ops { from[i] = op.from }
+    j := m.jobs.add("copy", targetLabel(from), copyJob(ops, m.copyOpts, m.journal.newBatch()))
+    j.copy = &copyProgress{}
+    return m.jobs.run(j)
+}
+
+// copyJob copies the ops in order, stopping at the first failure; a retry
+// starts again from the op that failed.
+func copyJob(ops []op, opt copyOptions, batch int64) jobFunc {
+    return func(ctx context.Context, j *job) (tea.Msg, error) {
+        p := j.copy
+        if p == nil { p = &copyProgress{} }
+        from := make([]string, len(ops))
+        for i, op := range ops { from[i] = op.from }
+        p.total.Store(copyTotal(from))
+        c := &copier{ctx: ctx, opt: opt, p: p}
+        msg := copyDoneMsg{batch: batch, verify: opt.verify}
+        var err error
+        for k, op := range ops {
+            before := len(c.done)
+            if err = c.copyTop(op.from, op.to); err != nil {
+                j.retry = copyJob(ops[k:], opt, batch)
+                break
+            }
+            if len(c.done) > before { msg.n++ }
+        }
+        msg.skipped, msg.done = c.skipped, c.done
+        return msg, err
+    }
+}
+
+func (m *model) applyCopyDone(msg copyDoneMsg) tea.Cmd {
+    s := fmt.Sprintf("copied %d item(s)", msg.n)
+    if msg.skipped > 0 { s += fmt.Sprintf(", %d skipped", msg.skipped) }
+    if msg.verify { s += ", verified" }
+    for _, op := range msg.done { m.journal.record(msg.batch, "copy", op) }
+    m.status = s
+    reload := m.reloadList()
+    return tea.Batch(reload, m.refreshPeer())
+}
+
+// copyPreviewKey changes the conflict policy or verification in the copy
+// preview; it reports whether it used the key.
+func (m *model) copyPreviewKey(k string) bool {
+    switch k {
+    case "p": m.copyOpts.policy = (m.copyOpts.policy + 1) % numConflictPolicies
+    case "v": m.copyOpts.verify = !m.copyOpts.verify
+    default: return false
+    }
+    m.opsOverlayText = m.copyPlanText()
+    m.opsOverlay.SetContent(m.opsOverlayText)
+    return true
+}
+
+// copyPlanText is the copy dry-run: what happens to each entry under the
+// current policy.
+func (m *model) copyPlanText() string {
+    b := &strings.Builder{}
+    dst := ""
+    if len(m.pendingOps) > 0 { dst = filepath.Dir(m.pendingOps[0].to) }
+    verify := "off"
+    if m.copyOpts.verify { verify = "on" }
+    fmt.Fprintf(b, "Copy preview → %s\n", dst)
+    fmt.Fprintf(b, "on conflict: %s · verify: %s   (p policy, v verify)\n\n", conflictNames[m.copyOpts.policy], verify)
+    for _, op := range m.pendingOps {
+        to, note := op.to, ""
+        if dfi, err := os.Lstat(op.to); err == nil {
+            sfi, serr := os.Lstat(op.from)
+            merge := serr == nil && sfi.IsDir() && dfi.IsDir()
+            switch {
+            case m.copyOpts.policy == conflictRename: to = uniqueDest(dst, filepath.Base(op.to))
+            case merge: note = "  (merged into existing directory)"
+            case m.copyOpts.policy == conflictSkip: note = "  (exists, skipped)"
+            case m.copyOpts.policy == conflictOverwrite: note = "  (replaces existing)"
+            default: note = "  (replaces existing if newer)"
+            }
+        }
+        fmt.Fprintf(b, "%s\n  ↳ %s%s\n\n", op.from, to, note)
+    }
+    return b.String()
+}
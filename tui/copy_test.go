@@ -0,0 +1,104 @@
+package main
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func newTestCopier(policy conflictPolicy) *copier {
+    return &copier{ctx: context.Background(), opt: copyOptions{policy: policy, verify: true}, p: &copyProgress{}}
+}
+
+func writeAt(t *testing.T, p, content string, mtime time.Time) {
+    t.Helper()
+    if err := os.WriteFile(p, []byte(content), 0o644); err != nil { t.Fatal(err) }
+    if err := os.Chtimes(p, mtime, mtime); err != nil { t.Fatal(err) }
+}
+
+func readFile(t *testing.T, p string) string {
+    t.Helper()
+    b, err := os.ReadFile(p)
+    if err != nil { t.Fatal(err) }
+    return string(b)
+}
+
+func TestCopyPolicies(t *testing.T) {
+    old, recent := time.Now().Add(-time.Hour), time.Now()
+    tests := []struct {
+        name      string
+        policy    conflictPolicy
+        srcNewer  bool
+        wantDest  string // content left at the destination name
+        wantOther string // name of the "(n)" copy, if any
+        journaled bool
+        replaced  bool
+        skipped   int
+    }{
+        {"rename", conflictRename, true, "old", "f (1).txt", true, false, 0},
+        {"skip", conflictSkip, true, "old", "", false, false, 1},
+        {"overwrite", conflictOverwrite, false, "new", "", true, true, 0},
+        {"newer wins, source newer", conflictNewer, true, "new", "", true, true, 0},
+        {"newer wins, source older", conflictNewer, false, "old", "", false, false, 1},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            src, dst := t.TempDir(), t.TempDir()
+            srcTime, dstTime := old, recent
+            if tt.srcNewer { srcTime, dstTime = recent, old }
+            writeAt(t, filepath.Join(src, "f.txt"), "new", srcTime)
+            writeAt(t, filepath.Join(dst, "f.txt"), "old", dstTime)
+            c := newTestCopier(tt.policy)
+            if err := c.copyTop(filepath.Join(src, "f.txt"), filepath.Join(dst, "f.txt")); err != nil { t.Fatal(err) }
+            if got := readFile(t, filepath.Join(dst, "f.txt")); got != tt.wantDest { t.Errorf("destination = %q, want %q", got, tt.wantDest) }
+            if tt.wantOther != "" {
+                if got := readFile(t, filepath.Join(dst, tt.wantOther)); got != "new" { t.Errorf("%s = %q, want the copy", tt.wantOther, got) }
+            }
+            if c.skipped != tt.skipped { t.Errorf("skipped = %d, want %d", c.skipped, tt.skipped) }
+            if !tt.journaled {
+                if len(c.done) != 0 { t.Errorf("journalled %+v for a kept entry", c.done) }
+                return
+            }
+            if len(c.done) != 1 { t.Fatalf("journalled %d ops, want 1", len(c.done)) }
+            if c.done[0].Replaced != tt.replaced { t.Errorf("Replaced = %v, want %v", c.done[0].Replaced, tt.replaced) }
+            if tt.wantOther != "" && filepath.Base(c.done[0].To) != tt.wantOther { t.Errorf("To = %s, want %s", c.done[0].To, tt.wantOther) }
+        })
+    }
+}
+
+func TestCopyMergeDir(t *testing.T) {
+    src, dst := t.TempDir(), t.TempDir()
+    now := time.Now()
+    os.MkdirAll(filepath.Join(src, "d"), 0o755)
+    os.MkdirAll(filepath.Join(dst, "d"), 0o755)
+    writeAt(t, filepath.Join(src, "d", "same"), "new", now)
+    writeAt(t, filepath.Join(dst, "d", "same"), "old", now)
+    writeAt(t, filepath.Join(src, "d", "added"), "added", now)
+
+    c := newTestCopier(conflictSkip)
+    if err := c.copyTop(filepath.Join(src, "d"), filepath.Join(dst, "d")); err != nil { t.Fatal(err) }
+    if got := readFile(t, filepath.Join(dst, "d", "same")); got != "old" { t.Errorf("existing file = %q, want it kept", got) }
+    if got := readFile(t, filepath.Join(dst, "d", "added")); got != "added" { t.Errorf("new file = %q", got) }
+    if len(c.done) != 1 || !c.done[0].Replaced { t.Errorf("journal = %+v, want one merged op", c.done) }
+
+    // everything already there: nothing changes, nothing is journalled
+    c = newTestCopier(conflictSkip)
+    if err := c.copyTop(filepath.Join(src, "d"), filepath.Join(dst, "d")); err != nil { t.Fatal(err) }
+    if len(c.done) != 0 { t.Errorf("journal = %+v, want nothing", c.done) }
+}
+
+func TestCopyIntoItself(t *testing.T) {
+    dir := t.TempDir()
+    src := filepath.Join(dir, "d")
+    os.MkdirAll(src, 0o755)
+    c := newTestCopier(conflictRename)
+    err := c.copyTop(src, filepath.Join(src, "sub", "d"))
+    if err == nil || !strings.Contains(err.Error(), "into itself") { t.Fatalf("err = %v, want into-itself error", err) }
+    if _, err := os.Lstat(filepath.Join(src, "sub")); !os.IsNotExist(err) { t.Errorf("something was created inside the source") }
+
+    // a sibling sharing the name prefix is fine
+    if err := c.copyTop(src, filepath.Join(dir, "d2")); err != nil { t.Errorf("copy to sibling: %v", err) }
+}
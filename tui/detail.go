@@ -0,0 +1,334 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+    tea "github.com/charmbracelet/bubbletea"
+    "github.com/mattn/go-runewidth"
+)
+
+// ---------- Detail view (ls -l style columns) ----------
+
+type detailCol int
+
+const (
+    colPerms detailCol = iota
+    colLinks
+    colOwner
+    colSize
+    colMTime
+    colGit
+    numDetailCols
+)
+
+var detailColNames = [numDetailCols]string{"perms", "links", "owner", "size", "mtime", "git"}
+var detailColLetters = [numDetailCols]string{"p", "l", "o", "s", "m", "g"}
+var detailColHeaders = [numDetailCols]string{"Perms", "Ln", "Owner", "Size", "Modified", "Git"}
+
+// detailDropOrder is the order columns give way in when the pane is too
+// narrow to keep minNameWidth for names.
+var detailDropOrder = []detailCol{colLinks, colOwner, colGit, colPerms, colMTime, colSize}
+
+const minNameWidth = 12
+
+func defaultColWidth(c detailCol) int {
+    switch c {
+    case colPerms: return 10
+    case colLinks: return 3
+    case colOwner: return 16
+    case colSize:
+        if unitScheme() == "bytes" { return 14 }
+        return 8
+    case colMTime: return 16
+    }
+    return 3
+}
+
+type detailColumn struct {
+    col   detailCol
+    width int
+}
+
+// detailConfig is the column layout: FINFOTUI_COLUMNS picks columns and
+// widths (e.g. "perms,owner:12,size,mtime"), FINFOTUI_TIME=relative shows
+// mtime as an age and FINFOTUI_DETAIL=1 starts in the detail view.
+type detailConfig struct {
+    cols    []detailColumn
+    relTime bool
+}
+
+func detailConfigFromEnv() (detailConfig, bool) {
+    var dc detailConfig
+    if v := strings.TrimSpace(os.Getenv("FINFOTUI_COLUMNS")); v != "" {
+        for _, f := range strings.Split(v, ",") {
+            name, w, _ := strings.Cut(strings.TrimSpace(f), ":")
+            c, ok := parseDetailCol(name)
+            if !ok || dc.has(c) { continue }
+            col := detailColumn{col: c, width: defaultColWidth(c)}
+            if n, err := strconv.Atoi(w); err == nil && n > 0 { col.width = n }
+            dc.cols = append(dc.cols, col)
+        }
+    }
+    if dc.cols == nil {
+        for c := detailCol(0); c < numDetailCols; c++ { dc.cols = append(dc.cols, detailColumn{col: c, width: defaultColWidth(c)}) }
+    }
+    dc.relTime = strings.EqualFold(os.Getenv("FINFOTUI_TIME"), "relative")
+    on, _ := strconv.ParseBool(os.Getenv("FINFOTUI_DETAIL"))
+    return dc, on
+}
+
+func parseDetailCol(s string) (detailCol, bool) {
+    s = strings.ToLower(s)
+    for c := detailCol(0); c < numDetailCols; c++ {
+        if s == detailColNames[c] || s == detailColLetters[c] { return c, true }
+    }
+    switch s {
+    case "mode", "permissions": return colPerms, true
+    case "nlink", "link": return colLinks, true
+    case "user", "group": return colOwner, true
+    case "time", "modified": return colMTime, true
+    }
+    return 0, false
+}
+
+func (dc *detailConfig) has(c detailCol) bool {
+    for _, col := range dc.cols { if col.col == c { return true } }
+    return false
+}
+
+// toggle shows or hides c; a shown column goes back to its default slot.
+func (dc *detailConfig) toggle(c detailCol) {
+    for i, col := range dc.cols {
+        if col.col == c { dc.cols = append(dc.cols[:i:i], dc.cols[i+1:]...); return }
+    }
+    at := len(dc.cols)
+    for i, col := range dc.cols { if col.col > c { at = i; break } }
+    dc.cols = append(dc.cols[:at:at], append([]detailColumn{{col: c, width: defaultColWidth(c)}}, dc.cols[at:]...)...)
+}
+
+// fit returns the columns that leave at least minNameWidth for names in a
+// row of width w, and the name width that remains.
+func (dc *detailConfig) fit(w int) ([]detailColumn, int) {
+    cols := dc.cols
+    name := func() int {
+        n := w
+        for _, col := range cols { n -= col.width + 1 }
+        return n
+    }
+    for _, drop := range detailDropOrder {
+        if name() >= minNameWidth { break }
+        for i, col := range cols {
+            if col.col == drop { cols = append(cols[:i:i], cols[i+1:]...); break }
+        }
+    }
+    n := name()
+    if n < 1 { n = 1 }
+    return cols, n
+}
+
+// ---------- Cells ----------
+
+// permString renders mode like ls -l, including setuid/setgid/sticky.
+func permString(typ byte, mode uint32) string {
+    b := []byte{typ, '-', '-', '-', '-', '-', '-', '-', '-', '-'}
+    const rwx = "rwx"
+    for i := 0; i < 9; i++ {
+        if mode&(1<<uint(8-i)) != 0 { b[i+1] = rwx[i%3] }
+    }
+    special := func(bit uint32, at int, set, unset byte) {
+        if mode&bit == 0 { return }
+        if b[at] == 'x' { b[at] = set } else { b[at] = unset }
+    }
+    special(04000, 3, 's', 'S')
+    special(02000, 6, 's', 'S')
+    special(01000, 9, 't', 'T')
+    return string(b)
+}
+
+func (l *vlist) detailCell(c detailCol, i int, now time.Time) string {
+    if c == colGit {
+        if !l.git.repo { return "" }
+        code, ok := l.git.codes[l.store.path(i)]
+        if !ok { return "--" }
+        return strings.ReplaceAll(code, " ", "-")
+    }
+    em, ok := l.store.metaAt(i)
+    if !ok { return "·" }
+    switch c {
+    case colPerms:
+        return permString(em.typ, em.mode)
+    case colLinks:
+        return strconv.FormatUint(uint64(em.nlink), 10)
+    case colOwner:
+        u, g := inspect.OwnerNames(em.uid, em.gid)
+        return u + ":" + g
+    case colSize:
+        if em.typ == 'd' && l.tree != nil {
+            if u := l.tree.usage[int32(i)]; u.done { return sizeFmt(u.bytes, unitScheme()) }
+        }
+        if em.typ == 'd' { return "-" }
+        return sizeFmt(em.size, unitScheme())
+    case colMTime:
+        if em.mtime == 0 { return "?" }
+        t := time.Unix(0, em.mtime)
+        if l.cols.relTime { return fmtAgo(now.Sub(t)) }
+        return t.Format("2006-01-02 15:04")
+    }
+    return ""
+}
+
+// detailRow lays out one entry (or the header when i < 0) in width w.
+func (l *vlist) detailRow(i int, cols []detailColumn, nameW int, now time.Time) string {
+    var b strings.Builder
+    if i < 0 {
+        b.WriteString("  ")
+        b.WriteString(runewidth.FillRight("Name", nameW))
+    } else {
+        mark := "  "
+        if l.store.flags[i]&entrySel != 0 { mark = "✓ " }
+        name := string(l.store.nameBytes(i))
+        if l.store.flags[i]&entryDir != 0 { name += "/" }
+        if l.tree != nil { name = l.treeLabel(i, false) } // subtree sizes go in the size column
+        if st := l.states[l.store.path(i)]; st != previewIdle { name += " · " + st.String() }
+        b.WriteString(mark)
+        b.WriteString(runewidth.FillRight(runewidth.Truncate(name, nameW, "…"), nameW))
+    }
+    for _, col := range cols {
+        cell := detailColHeaders[col.col]
+        if i >= 0 { cell = l.detailCell(col.col, i, now) }
+        cell = runewidth.Truncate(cell, col.width, "…")
+        b.WriteByte(' ')
+        if col.col == colSize || col.col == colLinks {
+            b.WriteString(runewidth.FillLeft(cell, col.width))
+        } else {
+            b.WriteString(runewidth.FillRight(cell, col.width))
+        }
+    }
+    return b.String()
+}
+
+// ---------- Stat and git data for visible rows ----------
+
+// statVisible stats the visible rows that have no metadata yet, so the
+// detail view fills in without waiting for a whole-directory pass.
+func (m *model) statVisible() tea.Cmd {
+    if !m.files.detail { return nil }
+    end := m.files.offset + m.files.perPage()
+    if end > len(m.files.view) { end = len(m.files.view) }
+    var idx []int32
+    for pos := m.files.offset; pos < end; pos++ {
+        i := m.files.view[pos]
+        if m.files.store.flags[i]&(entryStat|entryStatPending) != 0 { continue }
+        m.files.store.flags[i] |= entryStatPending
+        idx = append(idx, i)
+    }
+    if len(idx) == 0 { return nil }
+    return statEntries(context.Background(), m.scanSeq, m.files.store, idx, false)
+}
+
+// gitStatus holds `git status` codes for the listed entries; a directory
+// carries the code of a changed path below it.
+type gitStatus struct {
+    gen   int
+    repo  bool
+    codes map[string]string
+}
+
+type gitStatusMsg gitStatus
+
+// loadGitStatus fetches git status for the browsed directory when the git
+// column is shown. gen is the scan sequence number.
+func (m *model) loadGitStatus() tea.Cmd {
+    if !m.files.detail || !m.browsing || !m.files.cols.has(colGit) || m.files.git.gen == m.scanSeq { return nil }
+    if which("git") == "" { return nil }
+    m.files.git = gitStatus{gen: m.scanSeq}
+    gen, dir := m.scanSeq, m.cwd
+    return func() tea.Msg {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        top, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+        if err != nil { return gitStatusMsg{gen: gen} }
+        out, err := exec.CommandContext(ctx, "git", "--no-optional-locks", "-C", dir, "status", "--porcelain=v1", "-z", "--", ".").Output()
+        if err != nil { return gitStatusMsg{gen: gen} }
+        return gitStatusMsg{gen: gen, repo: true, codes: parseGitStatus(out, strings.TrimSpace(string(top)), dir)}
+    }
+}
+
+// parseGitStatus maps `git status --porcelain=v1 -z` output (paths relative
+// to the repository root top) to the entries of dir.
+func parseGitStatus(out []byte, top, dir string) map[string]string {
+    codes := map[string]string{}
+    real := dir
+    if r, err := filepath.EvalSymlinks(dir); err == nil { real = r }
+    if abs, err := filepath.Abs(real); err == nil { real = abs }
+    fields := bytes.Split(out, []byte{0})
+    for k := 0; k < len(fields); k++ {
+        f := fields[k]
+        if len(f) < 4 { continue }
+        code, p := string(f[:2]), string(f[3:])
+        if code[0] == 'R' || code[0] == 'C' { k++ } // the next field is the source path
+        rel, err := filepath.Rel(real, filepath.Join(top, strings.TrimSuffix(p, "/")))
+        if err != nil || rel == "." || strings.HasPrefix(rel, "..") { continue }
+        first, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+        key := filepath.Join(dir, first)
+        // untracked or ignored codes give way to changes in the same directory
+        if old, ok := codes[key]; ok && old != "??" && old != "!!" { continue }
+        codes[key] = code
+    }
+    return codes
+}
+
+func (m *model) applyGitStatus(msg gitStatusMsg) {
+    if msg.gen != m.scanSeq { return }
+    m.files.git = gitStatus(msg)
+}
+
+// toggleDetail switches the file pane between two-line rows and columns.
+func (m *model) toggleDetail() tea.Cmd {
+    m.files.detail = !m.files.detail
+    m.files.scroll()
+    if m.files.detail { m.status = "detail view" } else { m.status = "" }
+    return m.loadGitStatus()
+}
+
+// columnsMenuKey handles a key in the column picker overlay.
+func (m *model) columnsMenuKey(k string) tea.Cmd {
+    switch k {
+    case "esc", "q", "C", "enter":
+        m.mode = modeList
+        return nil
+    case "T":
+        m.files.cols.relTime = !m.files.cols.relTime
+        return nil
+    }
+    for c := detailCol(0); c < numDetailCols; c++ {
+        if k != detailColLetters[c] { continue }
+        m.files.cols.toggle(c)
+        if c != colGit { return nil }
+        m.files.git.gen = 0 // the column came back (or went away); refetch
+        return m.loadGitStatus()
+    }
+    return nil
+}
+
+func columnsMenu(dc detailConfig) string {
+    var b strings.Builder
+    b.WriteString("Columns\n\n")
+    for c := detailCol(0); c < numDetailCols; c++ {
+        mark := "[ ]"
+        if dc.has(c) { mark = "[x]" }
+        b.WriteString("  " + detailColLetters[c] + "  " + mark + " " + detailColNames[c] + "\n")
+    }
+    tm := "absolute"
+    if dc.relTime { tm = "relative" }
+    b.WriteString("\n  T  mtime: " + tm + "\n\nesc to close")
+    return b.String()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    tea "github.com/charmbracelet/bubbletea"
+    "github.com/charmbracelet/lipgloss"
+)
+
+// ---------- Dual-pane (commander) layout ----------
+
+// The dual layout shows the active tab next to a peer tab. The peer keeps
+// the listing it had when it was stashed and is refreshed after transfers;
+// tab moves the focus across, which makes the peer the active tab.
+
+// peerTab is the index of the other pane's tab, or -1 outside the dual
+// layout.
+func (m *model) peerTab() int {
+    if m.layout.kind != layoutDual || m.peer == m.tab || m.peer < 0 || m.peer >= len(m.tabs) { return -1 }
+    return m.peer
+}
+
+// fixPeer keeps the peer valid after tabs open or close; without a second
+// tab the dual layout falls back to the split one.
+func (m *model) fixPeer() {
+    if m.layout.kind != layoutDual { return }
+    if len(m.tabs) < 2 {
+        m.layout.kind = layoutSplit
+        m.applyLayout()
+        return
+    }
+    if m.peer == m.tab || m.peer < 0 || m.peer >= len(m.tabs) { m.peer = (m.tab + 1) % len(m.tabs) }
+}
+
+// enterDual makes sure there is a tab for the other pane, opening the
+// current directory again when there is only one.
+func (m *model) enterDual() tea.Cmd {
+    if len(m.tabs) < 2 {
+        fl := newVList(m.previewStates)
+        fl.cols, fl.detail = m.files.cols, m.files.detail
+        dir := m.cwd
+        if !m.browsing { dir = "." }
+        m.tabs = append(m.tabs, workspace{files: fl, browsing: true, cwd: dir, sort: m.sort})
+        m.peer = len(m.tabs) - 1
+    }
+    m.fixPeer()
+    m.applyLayout()
+    return m.refreshPeer()
+}
+
+type peerListMsg struct {
+    tab      int
+    cwd      string
+    items    []fileItem
+    metas    []entryMeta // filled when the peer's sort key needs stat data
+    readErrs int         // batches that could not be read
+}
+
+// refreshPeer reloads the other pane's listing in the background.
+func (m *model) refreshPeer() tea.Cmd {
+    i := m.peerTab()
+    if i < 0 || !m.tabs[i].browsing { return nil }
+    cwd, withMeta := m.tabs[i].cwd, m.tabs[i].sort.Key.needsStat()
+    return func() tea.Msg {
+        msg := peerListMsg{tab: i, cwd: cwd}
+        readDirStream(context.Background(), cwd, func(it fileItem) bool { msg.items = append(msg.items, it); return true }, &msg.readErrs)
+        if withMeta {
+            msg.metas = make([]entryMeta, len(msg.items))
+            for k, it := range msg.items {
+                if fi, err := os.Lstat(it.path); err == nil { msg.metas[k] = metaOf(fi) } else { msg.metas[k].typ = '?' }
+            }
+        }
+        return msg
+    }
+}
+
+func (m *model) applyPeerList(msg peerListMsg) {
+    if msg.tab == m.tab || msg.tab >= len(m.tabs) || m.tabs[msg.tab].cwd != msg.cwd { return }
+    ws := &m.tabs[msg.tab]
+    sel := map[string]bool{}
+    for _, it := range ws.files.selectedItems() { sel[it.path] = true }
+    focus := ""
+    if it, ok := ws.files.selectedItem(); ok { focus = it.path }
+    for k := range msg.items { msg.items[k].selected = sel[msg.items[k].path] }
+    ws.files.setItems(msg.items)
+    for k, em := range msg.metas { ws.files.store.setMeta(k, em) }
+    ws.files.sortInfo = ws.sort.String()
+    if msg.readErrs > 0 { ws.files.sortInfo += fmt.Sprintf(" · %d read errors", msg.readErrs) }
+    ws.files.sortBy(ws.sort.less(&ws.files.store, false))
+    if pos := ws.files.find(focus); pos >= 0 { ws.files.selectPos(pos) } else { ws.files.top() }
+}
+
+// focusPeer moves the focus to the other pane.
+func (m *model) focusPeer() tea.Cmd {
+    i := m.peerTab()
+    if i < 0 { return nil }
+    m.peer = m.tab
+    return m.switchTab(i)
+}
+
+// dualView renders both panes in tab order, the focused one with its title
+// highlighted.
+func (m *model) dualView() string {
+    i := m.peerTab()
+    active := m.files
+    active.title = m.tabName(m.tab)
+    if i < 0 { return active.View() }
+    peer := m.tabs[i].files
+    peer.title = m.tabName(i)
+    peer.titleStyle = m.theme.status
+    if i < m.tab { return lipgloss.JoinHorizontal(lipgloss.Top, peer.View(), " ", active.View()) }
+    return lipgloss.JoinHorizontal(lipgloss.Top, active.View(), " ", peer.View())
+}
+
+// transferInput asks for the destination of a move, copy or symlink; in
+// the dual layout it defaults to the other pane's directory.
+func (m *model) transferInput(act action) {
+    m.pendingAct = act
+    m.mode = modeMoveToDir
+    m.filter.Placeholder = "destination directory"
+    m.filter.SetValue("")
+    if i := m.peerTab(); i >= 0 && m.tabs[i].browsing {
+        dst := m.tabs[i].cwd
+        if abs, err := filepath.Abs(dst); err == nil { dst = abs }
+        m.filter.SetValue(dst)
+        m.filter.CursorEnd()
+    }
+    m.filter.Focus()
+}
+
+// symlinkEntry links to the absolute path of from at to.
+func symlinkEntry(from, to string) error {
+    abs, err := filepath.Abs(from)
+    if err != nil { return err }
+    return os.Symlink(abs, to)
+}
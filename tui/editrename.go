@@ -0,0 +1,132 @@
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "strconv"
+    "strings"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Rename in $EDITOR ----------
+
+// Like vidir: the targets go to a temporary file, one numbered line each,
+// relative to the directory they share. Whatever the lines say after the
+// editor exits becomes the rename plan; lines that are removed or left alone
+// leave their entry as it is.
+
+type editRename struct {
+    file  string
+    dir   string   // paths in the file are relative to dir
+    paths []string // by line number, from 1
+}
+
+type editDoneMsg struct{ err error }
+
+// editor is $VISUAL or $EDITOR split into a command and arguments.
+func editor() []string {
+    for _, v := range []string{"VISUAL", "EDITOR"} {
+        if f := strings.Fields(os.Getenv(v)); len(f) > 0 { return f }
+    }
+    if runtime.GOOS == "windows" { return []string{"notepad"} }
+    return []string{"vi"}
+}
+
+// commonDir is the deepest directory holding every path.
+func commonDir(paths []string) string {
+    dir := filepath.Dir(paths[0])
+    for _, p := range paths[1:] {
+        for !within(filepath.Dir(p), dir) && filepath.Dir(dir) != dir { dir = filepath.Dir(dir) }
+    }
+    return dir
+}
+
+func within(p, dir string) bool {
+    return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// startEditRename writes the targets to a temporary file and suspends the
+// TUI for the editor.
+func (m *model) startEditRename() tea.Cmd {
+    targets := m.targetItems()
+    if len(targets) == 0 { return nil }
+    e := &editRename{}
+    for _, t := range targets {
+        p, err := filepath.Abs(t.path)
+        if err != nil { p = t.path }
+        e.paths = append(e.paths, p)
+    }
+    e.dir = commonDir(e.paths)
+    f, err := os.CreateTemp("", "finfo-rename-*.txt")
+    if err != nil { m.status = "rename: " + err.Error(); return nil }
+    e.file = f.Name()
+    w := bufio.NewWriter(f)
+    fmt.Fprintf(w, "# Edit the names after the numbers, save and quit. Paths are relative to\n# %s; removing a line leaves that entry alone.\n", e.dir)
+    for i, p := range e.paths {
+        rel, err := filepath.Rel(e.dir, p)
+        if err != nil { rel = p }
+        fmt.Fprintf(w, "%d\t%s\n", i+1, rel)
+    }
+    err = w.Flush()
+    if cerr := f.Close(); err == nil { err = cerr }
+    if err != nil { os.Remove(e.file); m.status = "rename: " + err.Error(); return nil }
+    m.edit = e
+    return m.runEditor()
+}
+
+func (m *model) runEditor() tea.Cmd {
+    args := append(editor(), m.edit.file)
+    c := exec.Command(args[0], args[1:]...)
+    return tea.ExecProcess(c, func(err error) tea.Msg { return editDoneMsg{err: err} })
+}
+
+// applyEditDone reads the edited file back into a plan for the ops preview.
+func (m *model) applyEditDone(msg editDoneMsg) {
+    e := m.edit
+    if e == nil { return }
+    if msg.err != nil { m.endEditRename(); m.status = "editor: " + msg.err.Error(); return }
+    ops, err := e.plan()
+    if err != nil {
+        m.endEditRename()
+        m.status = "rename: " + err.Error()
+        return
+    }
+    if len(ops) == 0 { m.endEditRename(); m.status = "nothing renamed"; m.mode = modeList; return }
+    m.showRenamePlan(actEditRename, "Rename preview  (edited in "+filepath.Base(editor()[0])+")", "", ops, nil)
+}
+
+// plan diffs the file against the original paths.
+func (e *editRename) plan() ([]op, error) {
+    f, err := os.Open(e.file)
+    if err != nil { return nil, err }
+    defer f.Close()
+    seen := map[int]bool{}
+    var ops []op
+    sc := bufio.NewScanner(f)
+    for ln := 1; sc.Scan(); ln++ {
+        line := strings.TrimRight(sc.Text(), "\r")
+        if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") { continue }
+        num, name, ok := strings.Cut(strings.TrimLeft(line, " "), "\t")
+        n, err := strconv.Atoi(num)
+        if !ok || err != nil { return nil, fmt.Errorf("line %d: expected a number, a tab and a name", ln) }
+        if n < 1 || n > len(e.paths) { return nil, fmt.Errorf("line %d: no entry %d", ln, n) }
+        if seen[n] { return nil, fmt.Errorf("line %d: entry %d appears twice", ln, n) }
+        seen[n] = true
+        if strings.TrimSpace(name) == "" { return nil, fmt.Errorf("line %d: empty name", ln) }
+        to := filepath.Clean(name)
+        if !filepath.IsAbs(to) { to = filepath.Join(e.dir, to) }
+        if from := e.paths[n-1]; to != from { ops = append(ops, op{from: from, to: to}) }
+    }
+    return ops, sc.Err()
+}
+
+// endEditRename removes the temporary file.
+func (m *model) endEditRename() {
+    if m.edit != nil { os.Remove(m.edit.file) }
+    m.edit = nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "io/fs"
+
+func fileIno(fi fs.FileInfo) uint64 { return 0 }
+
+func fileDev(fi fs.FileInfo) uint64 { return 0 }
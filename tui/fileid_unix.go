@@ -0,0 +1,20 @@
+//go:build unix
+
+package main
+
+import (
+    "io/fs"
+    "syscall"
+)
+
+// fileIno returns the inode number, or 0 when the platform doesn't expose one.
+func fileIno(fi fs.FileInfo) uint64 {
+    if st, ok := fi.Sys().(*syscall.Stat_t); ok { return uint64(st.Ino) }
+    return 0
+}
+
+// fileDev returns the device holding the file, or 0 when unknown.
+func fileDev(fi fs.FileInfo) uint64 {
+    if st, ok := fi.Sys().(*syscall.Stat_t); ok { return uint64(st.Dev) }
+    return 0
+}
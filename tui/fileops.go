@@ -0,0 +1,97 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "syscall"
+)
+
+// ---------- File operations ----------
+
+// uniqueDest returns dst/base, or "name (n).ext" in dst when that exists,
+// like Finder does.
+func uniqueDest(dst, base string) string {
+    try := filepath.Join(dst, base)
+    ext := filepath.Ext(base)
+    for n := 1; ; n++ {
+        if _, err := os.Lstat(try); os.IsNotExist(err) { return try }
+        try = filepath.Join(dst, fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(base, ext), n, ext))
+    }
+}
+
+// planTransfer builds the ops for moving or copying the targets into dst and
+// shows them in the dry-run overlay. Copies resolve name conflicts when they
+// run, under the policy picked in the overlay (see copy.go).
+func (m *model) planTransfer(act action, dst string) {
+    targets := m.targetItems()
+    ops := make([]op, 0, len(targets))
+    for _, t := range targets {
+        to := uniqueDest(dst, filepath.Base(t.path))
+        if act == actCopyTo { to = filepath.Join(dst, filepath.Base(t.path)) }
+        ops = append(ops, op{from: t.path, to: to})
+    }
+    m.pendingOps = ops
+    m.pendingAct = act
+    m.mode = modeOpsPreview
+    m.status = "enter to confirm, esc to cancel"
+    if act == actCopyTo {
+        m.opsOverlayText = m.copyPlanText()
+        m.opsOverlay.SetContent(m.opsOverlayText)
+        return
+    }
+    verb := "Move"
+    if act == actLinkTo { verb = "Symlink" }
+    b := &strings.Builder{}
+    fmt.Fprintf(b, "%s preview → %s\n\n", verb, dst)
+    for _, op := range ops {
+        note := ""
+        if act == actMoveToDir && otherDevice(op.from, dst) { note = "  (other filesystem: copy, verify, delete)" }
+        fmt.Fprintf(b, "%s\n  ↳ %s%s\n\n", op.from, op.to, note)
+    }
+    m.opsOverlayText = b.String()
+    m.opsOverlay.SetContent(m.opsOverlayText)
+}
+
+// otherDevice reports whether from and the directory dst are known to be on
+// different filesystems.
+func otherDevice(from, dst string) bool {
+    a, err := os.Lstat(from)
+    if err != nil { return false }
+    b, err := os.Stat(dst)
+    if err != nil { return false }
+    return fileDev(a) != 0 && fileDev(b) != 0 && fileDev(a) != fileDev(b)
+}
+
+// crossDevice reports whether a rename failed because the paths are on
+// different filesystems.
+func crossDevice(err error) bool {
+    var le *os.LinkError
+    if !errors.As(err, &le) { return false }
+    if errors.Is(le.Err, syscall.EXDEV) { return true }
+    // 17 is ERROR_NOT_SAME_DEVICE on Windows but EEXIST elsewhere
+    return runtime.GOOS == "windows" && le.Err == syscall.Errno(17)
+}
+
+// moveEntry renames from to to. When they are on different filesystems it
+// copies instead, verifying every file by checksum, and removes the source
+// only once the copy is complete; a failed copy is removed again. The note
+// says when and why the move fell back to copying. Cancelling ctx stops the
+// copy.
+func moveEntry(ctx context.Context, from, to string) (note string, err error) {
+    err = os.Rename(from, to)
+    if err == nil || !crossDevice(err) { return "", err }
+    if _, err := os.Lstat(to); err == nil { return "", fmt.Errorf("%s: already exists on the other filesystem", to) }
+    note = fmt.Sprintf("%s: copied across filesystems (%v)", from, errors.Unwrap(err))
+    c := &copier{ctx: ctx, opt: copyOptions{verify: true}, p: &copyProgress{}}
+    if err := c.copy(from, to); err != nil {
+        os.RemoveAll(to)
+        return note, err
+    }
+    if err := os.RemoveAll(from); err != nil { return note, fmt.Errorf("copied to %s but could not remove the source: %w", to, err) }
+    return note, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestMoveOntoDirIsNotCrossDevice(t *testing.T) {
+    dir := t.TempDir()
+    from, to := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+    os.MkdirAll(from, 0o755)
+    os.MkdirAll(to, 0o755)
+    os.WriteFile(filepath.Join(from, "x"), []byte("a"), 0o644)
+    os.WriteFile(filepath.Join(to, "y"), []byte("b"), 0o644)
+
+    err := os.Rename(from, to)
+    if err == nil { t.Skip("rename replaced a non-empty directory on this platform") }
+    if crossDevice(err) { t.Fatalf("crossDevice(%v) = true", err) }
+    if _, err := moveEntry(context.Background(), from, to); err == nil { t.Fatal("moveEntry onto a non-empty directory succeeded") }
+    if _, err := os.Stat(filepath.Join(to, "x")); !os.IsNotExist(err) { t.Errorf("source was merged into the destination") }
+    if _, err := os.Stat(filepath.Join(from, "x")); err != nil { t.Errorf("source lost: %v", err) }
+}
+
+// otherFS is a directory on a different filesystem than the temp dir, or "".
+func otherFS(t *testing.T) string {
+    t.Helper()
+    base := t.TempDir()
+    for _, cand := range []string{"/dev/shm", os.Getenv("FINFOTUI_TEST_OTHER_FS")} {
+        if cand == "" || !otherDevice(base, cand) { continue }
+        dir, err := os.MkdirTemp(cand, "finfo-test-")
+        if err != nil { continue }
+        t.Cleanup(func() { os.RemoveAll(dir) })
+        return dir
+    }
+    return ""
+}
+
+func TestMoveEntryCrossDevice(t *testing.T) {
+    other := otherFS(t)
+    if other == "" { t.Skip("no second filesystem (set FINFOTUI_TEST_OTHER_FS)") }
+    src := filepath.Join(t.TempDir(), "d")
+    os.MkdirAll(filepath.Join(src, "sub"), 0o755)
+    os.WriteFile(filepath.Join(src, "sub", "f"), []byte("data"), 0o644)
+
+    // cancelled: nothing moves and nothing is left behind
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    to := filepath.Join(other, "d")
+    if _, err := moveEntry(ctx, src, to); !errors.Is(err, context.Canceled) { t.Fatalf("err = %v, want context.Canceled", err) }
+    if _, err := os.Lstat(to); !os.IsNotExist(err) { t.Errorf("partial copy left at %s", to) }
+    if _, err := os.Stat(filepath.Join(src, "sub", "f")); err != nil { t.Fatalf("source lost: %v", err) }
+
+    note, err := moveEntry(context.Background(), src, to)
+    if err != nil { t.Fatal(err) }
+    if note == "" { t.Errorf("no note about copying across filesystems") }
+    if b, err := os.ReadFile(filepath.Join(to, "sub", "f")); err != nil || string(b) != "data" { t.Errorf("copied file = %q, %v", b, err) }
+    if _, err := os.Lstat(src); !os.IsNotExist(err) { t.Errorf("source still there") }
+}
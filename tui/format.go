@@ -0,0 +1,64 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "strings"
+    "time"
+)
+
+// ---------- Formatting helpers (ports of lib/_size.zsh) ----------
+
+// unitScheme returns the size unit scheme (bytes|iec|si), honouring
+// FINFO_UNIT like `finfo --unit`.
+func unitScheme() string {
+    switch v := strings.ToLower(os.Getenv("FINFO_UNIT")); v {
+    case "bytes", "byte", "si", "iec": if v == "byte" { return "bytes" }; return v
+    }
+    return "iec"
+}
+
+// sizeFmt matches _hr_size_fmt.
+func sizeFmt(b int64, scheme string) string {
+    switch scheme {
+    case "bytes":
+        return fmt.Sprintf("%d B", b)
+    case "si":
+        switch {
+        case b >= 1e9: return fmt.Sprintf("%d GB", b/1e9)
+        case b >= 1e6: return fmt.Sprintf("%d MB", b/1e6)
+        case b >= 1e3: return fmt.Sprintf("%d kB", b/1e3)
+        }
+        return fmt.Sprintf("%d B", b)
+    }
+    switch {
+    case b >= 1<<30: return fmt.Sprintf("%d GiB", b>>30)
+    case b >= 1<<20: return fmt.Sprintf("%d MiB", b>>20)
+    case b >= 1<<10: return fmt.Sprintf("%d KiB", b>>10)
+    }
+    return fmt.Sprintf("%d B", b)
+}
+
+// fmtAgo matches _fmt_ago: at most two units, e.g. "3d 4h ago".
+func fmtAgo(d time.Duration) string {
+    secs := int64(d / time.Second)
+    if secs < 0 { secs = 0 }
+    units := []struct{ n int64; s string }{{31557600, "y"}, {2629800, "mo"}, {86400, "d"}, {3600, "h"}, {60, "m"}}
+    parts := make([]string, 0, 2)
+    for _, u := range units {
+        if len(parts) == 2 { break }
+        if v := secs / u.n; v > 0 { parts = append(parts, fmt.Sprintf("%d%s", v, u.s)) }
+        secs %= u.n
+    }
+    if len(parts) == 0 { parts = append(parts, "0m") }
+    return strings.Join(parts, " ") + " ago"
+}
+
+// ellipsizeMiddle matches _ellipsize_middle.
+func ellipsizeMiddle(s string, max int) string {
+    r := []rune(s)
+    if max < 3 || len(r) <= max { return s }
+    head := (max - 1) / 2
+    tail := max - head - 1
+    return string(r[:head]) + "…" + string(r[len(r)-tail:])
+}
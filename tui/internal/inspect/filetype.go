@@ -0,0 +1,103 @@
+package inspect
+
+import (
+    "bufio"
+    "fmt"
+    "image"
+    _ "image/gif"
+    _ "image/jpeg"
+    _ "image/png"
+    "os"
+    "regexp"
+    "strings"
+)
+
+// filetypeStats mirrors _compute_filetype_stats in lib/_filetype.zsh for the
+// facts that can be computed without external tools.
+func filetypeStats(p, name string) Filetype {
+    ft := Filetype{}
+    lc := strings.ToLower(name)
+    switch {
+    case hasSuffix(lc, ".png", ".jpg", ".jpeg", ".gif"):
+        if f, err := os.Open(p); err == nil {
+            if cfg, _, err := image.DecodeConfig(f); err == nil { ft.ImageDims = fmt.Sprintf("%dx%d", cfg.Width, cfg.Height) }
+            f.Close()
+        }
+    case hasSuffix(lc, ".md", ".markdown"):
+        n := 0
+        scanLines(p, func(line string) bool { if headingRe.MatchString(line) { n++ }; return true })
+        ft.Headings = &n
+    case hasSuffix(lc, ".csv", ".tsv", ".txt"):
+        first := ""
+        scanLines(p, func(line string) bool { if line != "" { first = line; return false }; return true })
+        if first == "" { break }
+        best, delim := 0, ""
+        for _, c := range []struct{ sep, name string }{{",", "comma"}, {";", "semicolon"}, {"\t", "tab"}, {"|", "pipe"}} {
+            if n := strings.Count(first, c.sep); n > best { best, delim = n, c.name }
+        }
+        if best > 0 { cols := best + 1; ft.Columns = &cols; ft.Delimiter = delim }
+    }
+    return ft
+}
+
+var headingRe = regexp.MustCompile(`^#{1,6} `)
+
+func hasSuffix(s string, suffixes ...string) bool {
+    for _, x := range suffixes { if strings.HasSuffix(s, x) { return true } }
+    return false
+}
+
+// scanLines feeds lines to fn until it returns false or the file ends.
+func scanLines(p string, fn func(string) bool) {
+    f, err := os.Open(p)
+    if err != nil { return }
+    defer f.Close()
+    sc := bufio.NewScanner(f)
+    sc.Buffer(make([]byte, 64<<10), 1<<20)
+    for sc.Scan() { if !fn(sc.Text()) { return } }
+}
+
+// aboutLine picks the one-line summary like the About selection in
+// _compute_filetype_stats.
+func aboutLine(d *Document) string {
+    ft := d.Filetype
+    switch {
+    case ft.ImageDims != "": return "Image " + ft.ImageDims
+    case ft.Pages != nil: return fmt.Sprintf("PDF %d pages", *ft.Pages)
+    case ft.Headings != nil: return fmt.Sprintf("Markdown %d headings", *ft.Headings)
+    case ft.Columns != nil:
+        s := fmt.Sprintf("Delimited %d columns", *ft.Columns)
+        if ft.Delimiter != "" { s += " (" + ft.Delimiter + ")" }
+        return s
+    }
+    if s := describeExt(d.Name); s != "" { return s }
+    desc := d.Type.Description
+    if i := strings.IndexByte(desc, ','); i >= 0 { desc = desc[:i] }
+    return desc
+}
+
+// describeExt matches _describe_ext in lib/_filetype.zsh.
+func describeExt(name string) string {
+    lc := strings.ToLower(name)
+    switch {
+    case hasSuffix(lc, ".py"): return "Python source"
+    case hasSuffix(lc, ".ipynb"): return "Jupyter notebook"
+    case hasSuffix(lc, ".js"): return "JavaScript source"
+    case hasSuffix(lc, ".ts"): return "TypeScript source"
+    case hasSuffix(lc, ".tsx", ".jsx"): return "React component"
+    case hasSuffix(lc, ".sh", ".bash", ".zsh"): return "Shell script"
+    case hasSuffix(lc, ".md", ".markdown"): return "Markdown document"
+    case hasSuffix(lc, ".json"): return "JSON data"
+    case hasSuffix(lc, ".yaml", ".yml"): return "YAML config"
+    case hasSuffix(lc, ".toml"): return "TOML config"
+    case hasSuffix(lc, ".ini", ".conf"): return "Configuration file"
+    case hasSuffix(lc, ".sql"): return "SQL script"
+    case lc == "dockerfile", hasSuffix(lc, "dockerfile"): return "Docker build recipe"
+    case lc == "makefile", hasSuffix(lc, ".mk"): return "Make build rules"
+    case hasSuffix(lc, ".csv", ".tsv"): return "Delimited text data"
+    case hasSuffix(lc, ".r"): return "R script"
+    case hasSuffix(lc, ".pdf"): return "PDF document"
+    case hasSuffix(lc, ".zip", ".tar", ".tgz", ".tar.gz", ".gz", ".bz2", ".xz", ".7z"): return "Archive/compressed"
+    }
+    return ""
+}
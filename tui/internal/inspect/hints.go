@@ -0,0 +1,177 @@
+package inspect
+
+import (
+    "context"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "sync"
+    "time"
+)
+
+var (
+    toolMu    sync.Mutex
+    toolCache = map[string]bool{}
+)
+
+// have reports whether cmd is on PATH, caching lookups for the session.
+func have(cmd string) bool {
+    toolMu.Lock(); defer toolMu.Unlock()
+    ok, seen := toolCache[cmd]
+    if !seen {
+        _, err := exec.LookPath(cmd)
+        ok = err == nil
+        toolCache[cmd] = ok
+    }
+    return ok
+}
+
+// HaveTool is the exported form of have for callers rendering tips.
+func HaveTool(cmd string) bool { return have(cmd) }
+
+// qualityHints mirrors _suggest_quality in lib/_actions.zsh.
+func qualityHints(name, p string) []string {
+    lc := strings.ToLower(name)
+    out := []string{}
+    add := func(tool, line string) { if have(tool) { out = append(out, line) } }
+    switch {
+    case hasSuffix(lc, ".py"):
+        add("ruff", "ruff .")
+        add("black", "black '"+p+"'")
+    case hasSuffix(lc, ".js", ".jsx", ".ts", ".tsx"):
+        add("eslint", "eslint '"+p+"'")
+        add("prettier", "prettier --write '"+p+"'")
+        add("cspell", "cspell '"+p+"'")
+    case hasSuffix(lc, ".json"):
+        add("jq", "jq . '"+p+"' > /dev/null")
+        add("prettier", "prettier --write '"+p+"'")
+    case hasSuffix(lc, ".yaml", ".yml"):
+        add("yamllint", "yamllint '"+p+"'")
+        add("yq", "yq e . '"+p+"' > /dev/null")
+    case hasSuffix(lc, ".md", ".markdown"):
+        add("markdownlint", "markdownlint '"+p+"'")
+        add("typos", "typos '"+p+"'")
+        add("vale", "vale '"+p+"'")
+    case hasSuffix(lc, ".sh", ".bash", ".zsh"):
+        add("shellcheck", "shellcheck '"+p+"'")
+        add("shfmt", "shfmt -w '"+p+"'")
+    case lc == "dockerfile", hasSuffix(lc, "dockerfile"):
+        add("hadolint", "hadolint '"+p+"'")
+    }
+    return out
+}
+
+// actionHints mirrors _action_hints, _docker_hint and _archive_hint.
+func actionHints(name, p string, isFile bool, desc string) []string {
+    lc := strings.ToLower(name)
+    out := []string{}
+    add := func(tool, line string) { if have(tool) { out = append(out, line) } }
+    if isFile {
+        if have("bat") { out = append(out, "bat '"+p+"'") } else { out = append(out, "less -S '"+p+"'") }
+    }
+    add("code", "code '"+p+"'")
+    add("subl", "subl '"+p+"'")
+    add("cursor", "cursor '"+p+"'")
+    switch {
+    case hasSuffix(lc, ".md", ".markdown"): add("glow", "glow '"+p+"'")
+    case hasSuffix(lc, ".json"): add("jq", "jq . '"+p+"'")
+    case hasSuffix(lc, ".yaml", ".yml"): add("yq", "yq e . '"+p+"'")
+    case hasSuffix(lc, ".py"): add("python3", "python3 '"+p+"'")
+    case hasSuffix(lc, ".sh", ".bash"): add("bash", "bash '"+p+"'")
+    case hasSuffix(lc, ".zsh"): out = append(out, "zsh '"+p+"'")
+    case hasSuffix(lc, ".r"): add("Rscript", "Rscript '"+p+"'")
+    case hasSuffix(lc, ".ipynb"): add("jupyter", "jupyter lab '"+p+"'")
+    }
+    if runtime.GOOS == "darwin" { out = append(out, "open '"+p+"'") }
+    // Docker
+    dir := filepath.Dir(p)
+    docker := []string{}
+    if lc == "dockerfile" || strings.HasSuffix(lc, ".dockerfile") { docker = append(docker, "docker build -t myimage:latest "+dir) }
+    if exists(filepath.Join(dir, "docker-compose.yml")) || exists(filepath.Join(dir, "compose.yml")) { docker = append(docker, "docker compose up -d") }
+    if len(docker) > 0 { out = append(out, strings.Join(docker, "; ")) }
+    // Archives
+    if isFile {
+        if ext := archiveHint(lc, p); ext != "" {
+            d := strings.ToLower(desc)
+            isArchive := false
+            for _, k := range []string{"zip", "tar", "gzip", "bzip2", "xz", "7-zip", "7zip", "archive", "compressed"} {
+                if strings.Contains(d, k) { isArchive = true; break }
+            }
+            if isArchive { out = append(out, ext) } else { out = append(out, "WARN: name suggests archive but content is not an archive") }
+        }
+    }
+    return out
+}
+
+func archiveHint(lc, p string) string {
+    switch {
+    case hasSuffix(lc, ".zip"): return "unzip '" + p + "'"
+    case hasSuffix(lc, ".tar.gz", ".tgz"): return "tar -xzf '" + p + "'"
+    case hasSuffix(lc, ".tar.bz2", ".tbz", ".tbz2"): return "tar -xjf '" + p + "'"
+    case hasSuffix(lc, ".tar.xz", ".txz"): return "tar -xJf '" + p + "'"
+    case hasSuffix(lc, ".tar"): return "tar -xf '" + p + "'"
+    case hasSuffix(lc, ".gz"): return "gunzip '" + p + "'"
+    case hasSuffix(lc, ".bz2"): return "bunzip2 '" + p + "'"
+    case hasSuffix(lc, ".xz"): return "unxz '" + p + "'"
+    case hasSuffix(lc, ".7z"): return "7z x '" + p + "'"
+    }
+    return ""
+}
+
+func exists(p string) bool { _, err := os.Stat(p); return err == nil }
+
+// ---------- Git ----------
+
+// gitInfo finds the enclosing work tree and reads the branch from HEAD
+// directly. The status flag needs the git binary and is only computed when
+// withStatus is set, matching _compute_git_info.
+func gitInfo(ctx context.Context, abs string, isDir bool, withStatus bool) Git {
+    g := Git{}
+    dir := abs
+    if !isDir { dir = filepath.Dir(abs) }
+    gitDir := ""
+    for d := dir; ; d = filepath.Dir(d) {
+        cand := filepath.Join(d, ".git")
+        if fi, err := os.Stat(cand); err == nil {
+            gitDir = cand
+            if !fi.IsDir() {
+                // Worktrees and submodules: ".git" file pointing at the real dir
+                if b, err := os.ReadFile(cand); err == nil && strings.HasPrefix(string(b), "gitdir:") {
+                    gitDir = strings.TrimSpace(strings.TrimPrefix(string(b), "gitdir:"))
+                    if !filepath.IsAbs(gitDir) { gitDir = filepath.Join(d, gitDir) }
+                }
+            }
+            break
+        }
+        if filepath.Dir(d) == d { return g }
+    }
+    g.Present = true
+    if b, err := os.ReadFile(filepath.Join(gitDir, "HEAD")); err == nil {
+        head := strings.TrimSpace(string(b))
+        if strings.HasPrefix(head, "ref: refs/heads/") {
+            g.Branch = strings.TrimPrefix(head, "ref: refs/heads/")
+        } else if len(head) >= 7 {
+            g.Branch = head[:7]
+        }
+    }
+    if withStatus && have("git") { g.Status = gitStatus(ctx, dir, abs) }
+    return g
+}
+
+func gitStatus(ctx context.Context, dir, abs string) string {
+    ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+    defer cancel()
+    out, err := exec.CommandContext(ctx, "git", "--no-optional-locks", "-C", dir, "status", "--porcelain", "--", abs).Output()
+    if err != nil { return "" }
+    line := strings.TrimRight(string(out), "\n")
+    switch {
+    case line == "": return "clean"
+    case strings.HasPrefix(line, "??"): return "untracked"
+    case len(line) >= 2 && (line[1] == 'M' || strings.HasPrefix(line, "M ")): return "modified"
+    case strings.HasPrefix(line, "A "): return "added"
+    case strings.HasPrefix(line, "D "): return "deleted"
+    }
+    return "changed"
+}
@@ -0,0 +1,361 @@
+// Package inspect gathers file metadata in-process and produces the same
+// document as `finfo --json`, so the TUI does not need to spawn the zsh
+// script for every preview.
+package inspect
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "net/http"
+    "os"
+    "os/user"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+    "unicode/utf8"
+)
+
+// ---------- Options ----------
+
+// Options tunes how much work Inspect does. The zero value matches
+// `finfo --brief --json`.
+type Options struct {
+    // Long enables the expensive facts gated behind --long in the zsh tool
+    // (currently: directory disk usage).
+    Long bool
+    // GitStatus runs `git status` for the clean/modified flag; the branch is
+    // always read from .git/HEAD without spawning git.
+    GitStatus bool
+    // MaxLineBytes skips line counting for text files larger than this.
+    // Zero means 64 MiB.
+    MaxLineBytes int64
+    // MaxWalk bounds the number of entries visited when computing directory
+    // disk usage; the size is left empty when the bound is hit. Zero means 20000.
+    MaxWalk int
+}
+
+func (o Options) maxLineBytes() int64 { if o.MaxLineBytes > 0 { return o.MaxLineBytes }; return 64 << 20 }
+func (o Options) maxWalk() int { if o.MaxWalk > 0 { return o.MaxWalk }; return 20000 }
+
+// statInfo holds the raw facts pulled out of the platform stat structure.
+type statInfo struct {
+    mode     uint32 // permission and special bits (07777)
+    uid, gid uint32
+    nlink    int64
+    blocks   int64 // 512-byte blocks, -1 when unknown
+    atime, mtime, ctime, btime time.Time
+}
+
+// ---------- Inspect ----------
+
+// Inspect builds the document for target without shelling out.
+func Inspect(target string, opt Options) (*Document, error) {
+    return InspectContext(context.Background(), target, opt)
+}
+
+// InspectContext is Inspect with cancellation: line counting, disk usage
+// and `git status` stop early once ctx is done, and ctx.Err() is returned.
+func InspectContext(ctx context.Context, target string, opt Options) (*Document, error) {
+    fi, err := os.Lstat(target)
+    if err != nil { return nil, err }
+    st := platformStat(fi)
+    d := &Document{SchemaVersion: SchemaVersion, Name: filepath.Base(target), Quality: []string{}, Actions: []string{}}
+    d.Path.Rel = target
+    if abs, err := filepath.Abs(target); err == nil {
+        d.Path.Abs = abs
+        if real, err := filepath.EvalSymlinks(abs); err == nil { d.Path.Abs = real }
+    }
+    // Symlink details (type and lines follow the link, like `file`/`wc`)
+    isLink := fi.Mode()&fs.ModeSymlink != 0
+    tfi := fi
+    if isLink {
+        d.Symlink.IsSymlink = 1
+        if t, err := os.Readlink(target); err == nil { d.Symlink.Target = t }
+        if s, err := os.Stat(target); err == nil { d.Symlink.TargetExists = 1; tfi = s }
+    }
+    d.IsDir = tfi.IsDir()
+    d.Size.Bytes = fi.Size()
+    d.Size.Human = HumanSize(fi.Size())
+    d.Perms.Symbolic = symbolicMode(fi.Mode(), st.mode)
+    d.Perms.Octal = strconv.FormatUint(uint64(st.mode), 8)
+    d.Perms.Explain = explainAccess(tfi, platformStat(tfi))
+    d.Owner.User, d.Owner.Group = lookupOwner(st.uid, st.gid)
+    nl := st.nlink
+    d.Links.Hardlinks = &nl
+    d.Dates = formatDates(st)
+    // Type, charset and lines
+    if d.IsDir {
+        d.Type = Type{Description: "directory", IsText: "n/a", Mime: "inode/directory"}
+        d.Dir = scanDirCounts(ctx, target, opt)
+    } else if tfi.Mode().IsRegular() {
+        d.Type, d.Lines = sniffFile(ctx, target, tfi.Size(), opt)
+        d.Filetype = filetypeStats(target, d.Name)
+    } else if isLink {
+        d.Type = Type{Description: "broken symbolic link to " + d.Symlink.Target, IsText: "binary", Mime: "inode/symlink"}
+    } else {
+        d.Type = specialType(tfi.Mode())
+    }
+    if !d.IsDir { d.About = aboutLine(d) }
+    d.Git = gitInfo(ctx, d.Path.Abs, d.IsDir, opt.GitStatus)
+    d.Quality = qualityHints(d.Name, d.Path.Abs)
+    d.Actions = append(append([]string{}, d.Quality...), actionHints(d.Name, d.Path.Abs, tfi.Mode().IsRegular(), d.Type.Description)...)
+    // macOS provenance checks (spctl/codesign/stapler) stay in finfo.zsh
+    d.Security = Security{Gatekeeper: "unknown", Codesign: Codesign{Status: "unknown"}, Notarization: "unknown", Quarantine: "no", Verdict: "unknown"}
+    if err := ctx.Err(); err != nil { return nil, err }
+    return d, nil
+}
+
+// HumanSize matches _hr_size in lib/_size.zsh (integer division, single-letter units).
+func HumanSize(b int64) string {
+    switch {
+    case b >= 1<<30: return fmt.Sprintf("%dG", b>>30)
+    case b >= 1<<20: return fmt.Sprintf("%dM", b>>20)
+    case b >= 1<<10: return fmt.Sprintf("%dK", b>>10)
+    }
+    return fmt.Sprintf("%dB", b)
+}
+
+func symbolicMode(m fs.FileMode, bits uint32) string {
+    b := []byte("----------")
+    switch {
+    case m&fs.ModeDir != 0: b[0] = 'd'
+    case m&fs.ModeSymlink != 0: b[0] = 'l'
+    case m&fs.ModeNamedPipe != 0: b[0] = 'p'
+    case m&fs.ModeSocket != 0: b[0] = 's'
+    case m&fs.ModeCharDevice != 0: b[0] = 'c'
+    case m&fs.ModeDevice != 0: b[0] = 'b'
+    }
+    const rwx = "rwxrwxrwx"
+    for i := 0; i < 9; i++ { if bits&(1<<uint(8-i)) != 0 { b[i+1] = rwx[i] } }
+    special := func(pos int, set bool, lower, upper byte) {
+        if !set { return }
+        if b[pos] == 'x' { b[pos] = lower } else { b[pos] = upper }
+    }
+    special(3, bits&04000 != 0, 's', 'S')
+    special(6, bits&02000 != 0, 's', 'S')
+    special(9, bits&01000 != 0, 't', 'T')
+    return string(b)
+}
+
+// explainAccess mirrors _perm_explain: read/write capability for the current
+// user plus "executable" for non-directories.
+func explainAccess(fi fs.FileInfo, st statInfo) string {
+    r, w, x := accessBits(st)
+    desc := "no-access"
+    switch {
+    case r && w: desc = "read-write"
+    case r: desc = "read-only"
+    case w: desc = "write-only"
+    }
+    if x && !fi.IsDir() { desc += ", executable" }
+    return desc
+}
+
+func accessBits(st statInfo) (r, w, x bool) {
+    uid := os.Getuid()
+    if uid == 0 { return true, true, st.mode&0111 != 0 }
+    shift := uint(0)
+    if uid >= 0 && uint32(uid) == st.uid {
+        shift = 6
+    } else if inGroup(st.gid) {
+        shift = 3
+    }
+    p := (st.mode >> shift) & 7
+    return p&4 != 0, p&2 != 0, p&1 != 0
+}
+
+func inGroup(gid uint32) bool {
+    if g := os.Getgid(); g >= 0 && uint32(g) == gid { return true }
+    groups, _ := os.Getgroups()
+    for _, g := range groups { if uint32(g) == gid { return true } }
+    return false
+}
+
+var (
+    ownerMu    sync.Mutex
+    userNames  = map[uint32]string{}
+    groupNames = map[uint32]string{}
+)
+
+// lookupOwner resolves uid/gid to names, caching results since the user
+// database rarely changes during a session.
+func lookupOwner(uid, gid uint32) (string, string) {
+    ownerMu.Lock(); defer ownerMu.Unlock()
+    u, ok := userNames[uid]
+    if !ok {
+        u = strconv.FormatUint(uint64(uid), 10)
+        if usr, err := user.LookupId(u); err == nil { u = usr.Username }
+        userNames[uid] = u
+    }
+    g, ok := groupNames[gid]
+    if !ok {
+        g = strconv.FormatUint(uint64(gid), 10)
+        if grp, err := user.LookupGroupId(g); err == nil { g = grp.Name }
+        groupNames[gid] = g
+    }
+    return u, g
+}
+
+func formatDates(st statInfo) Dates {
+    epoch := func(t time.Time) *int64 { v := t.Unix(); return &v }
+    d := Dates{Created: "unknown"}
+    if !st.btime.IsZero() {
+        d.Created = formatTime(st.btime)
+        d.CreatedEpoch = epoch(st.btime)
+    }
+    d.Modified = formatTime(st.mtime)
+    d.ModifiedEpoch = epoch(st.mtime)
+    if !st.atime.IsZero() {
+        d.Accessed = formatTime(st.atime)
+        d.AccessedEpoch = epoch(st.atime)
+    }
+    return d
+}
+
+func specialType(m fs.FileMode) Type {
+    t := Type{IsText: "binary"}
+    switch {
+    case m&fs.ModeNamedPipe != 0: t.Description = "fifo (named pipe)"; t.Mime = "inode/fifo"
+    case m&fs.ModeSocket != 0: t.Description = "socket"; t.Mime = "inode/socket"
+    case m&fs.ModeCharDevice != 0: t.Description = "character special"; t.Mime = "inode/chardevice"
+    case m&fs.ModeDevice != 0: t.Description = "block special"; t.Mime = "inode/blockdevice"
+    default: t.Description = "data"; t.Mime = "application/octet-stream"
+    }
+    return t
+}
+
+// scanDirCounts counts immediate non-hidden subdirectories and regular files,
+// like the zsh `*(/N)` and `*(.N)` globs.
+func scanDirCounts(ctx context.Context, dir string, opt Options) Dir {
+    out := Dir{}
+    entries, err := os.ReadDir(dir)
+    if err != nil { return out }
+    for _, e := range entries {
+        if strings.HasPrefix(e.Name(), ".") { continue }
+        if e.IsDir() { out.NumDirs++ } else if e.Type().IsRegular() { out.NumFiles++ }
+    }
+    if opt.Long {
+        if n, ok := diskUsage(ctx, dir, opt.maxWalk()); ok { out.SizeHuman = HumanSize(n) }
+    }
+    return out
+}
+
+var errWalkLimit = errors.New("walk limit")
+
+// diskUsage approximates `du -sk`, giving up after limit entries.
+func diskUsage(ctx context.Context, dir string, limit int) (int64, bool) {
+    var total int64
+    seen := 0
+    err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+        if ctx.Err() != nil { return ctx.Err() }
+        if err != nil { return nil }
+        seen++
+        if seen > limit { return errWalkLimit }
+        fi, err := d.Info()
+        if err != nil { return nil }
+        if st := platformStat(fi); st.blocks >= 0 { total += st.blocks * 512 } else { total += fi.Size() }
+        return nil
+    })
+    if err != nil { return 0, false }
+    return total, true
+}
+
+// ---------- Content sniffing ----------
+
+const sniffLen = 8192
+
+func sniffFile(ctx context.Context, p string, size int64, opt Options) (Type, *int64) {
+    f, err := os.Open(p)
+    if err != nil { return Type{Description: "regular file, no read permission", IsText: "binary"}, nil }
+    defer f.Close()
+    head := make([]byte, sniffLen)
+    n, _ := io.ReadFull(f, head)
+    head = head[:n]
+    if n == 0 {
+        return Type{Description: "empty", IsText: "text", Charset: "binary", Mime: "inode/x-empty"}, nil
+    }
+    t := classify(head)
+    if t.IsText != "text" || size > opt.maxLineBytes() { return t, nil }
+    // wc -l semantics: count newline bytes
+    if _, err := f.Seek(0, io.SeekStart); err != nil { return t, nil }
+    var lines int64
+    r := bufio.NewReaderSize(f, 64<<10)
+    buf := make([]byte, 64<<10)
+    for {
+        if ctx.Err() != nil { return t, nil }
+        k, err := r.Read(buf)
+        lines += int64(bytes.Count(buf[:k], []byte{'\n'}))
+        if err != nil { break }
+    }
+    return t, &lines
+}
+
+// classify derives a `file -b`-like description, MIME type and charset from
+// the first bytes of a file.
+func classify(head []byte) Type {
+    switch {
+    case bytes.HasPrefix(head, []byte("\x7fELF")):
+        return Type{Description: "ELF executable", IsText: "binary", Mime: "application/x-executable"}
+    case bytes.HasPrefix(head, []byte{0xcf, 0xfa, 0xed, 0xfe}), bytes.HasPrefix(head, []byte{0xce, 0xfa, 0xed, 0xfe}):
+        return Type{Description: "Mach-O executable", IsText: "binary", Mime: "application/x-mach-binary"}
+    case bytes.HasPrefix(head, []byte{0xca, 0xfe, 0xba, 0xbe}):
+        return Type{Description: "Mach-O universal binary", IsText: "binary", Mime: "application/x-mach-binary"}
+    }
+    if isText(head) {
+        t := Type{IsText: "text", Mime: "text/plain"}
+        ascii := true
+        for _, c := range head { if c >= 0x80 { ascii = false; break } }
+        if ascii { t.Charset = "us-ascii"; t.Description = "ASCII text" } else { t.Charset = "utf-8"; t.Description = "Unicode text, UTF-8 text" }
+        if bytes.HasPrefix(head, []byte("#!")) {
+            line := head
+            if i := bytes.IndexByte(head, '\n'); i >= 0 { line = head[:i] }
+            t.Description = strings.TrimSpace(string(line[2:])) + " script, " + t.Description + " executable"
+            t.Mime = "text/x-shellscript"
+        }
+        return t
+    }
+    mime := http.DetectContentType(head)
+    if i := strings.IndexByte(mime, ';'); i >= 0 { mime = mime[:i] }
+    return Type{Description: describeMime(mime), IsText: "binary", Mime: mime}
+}
+
+func isText(head []byte) bool {
+    if bytes.IndexByte(head, 0) >= 0 { return false }
+    // Tolerate a rune cut at the sniff boundary
+    for len(head) > 0 && !utf8.Valid(head) {
+        if len(head) < sniffLen-utf8.UTFMax { return false }
+        head = head[:len(head)-1]
+    }
+    for _, c := range head {
+        if c < 0x20 && c != '\n' && c != '\r' && c != '\t' && c != '\f' && c != 0x1b { return false }
+    }
+    return true
+}
+
+func describeMime(mime string) string {
+    switch mime {
+    case "image/png": return "PNG image data"
+    case "image/jpeg": return "JPEG image data"
+    case "image/gif": return "GIF image data"
+    case "image/webp": return "Web/P image data"
+    case "image/bmp": return "PC bitmap"
+    case "application/pdf": return "PDF document"
+    case "application/zip": return "Zip archive data"
+    case "application/x-gzip": return "gzip compressed data"
+    case "application/x-rar-compressed": return "RAR archive data"
+    case "application/wasm": return "WebAssembly (wasm) binary module"
+    case "audio/mpeg": return "Audio file with ID3"
+    case "audio/wave": return "RIFF (little-endian) data, WAVE audio"
+    case "video/mp4": return "ISO Media, MP4"
+    case "font/woff": return "Web Open Font Format"
+    case "font/woff2": return "Web Open Font Format (Version 2)"
+    }
+    return "data"
+}
@@ -0,0 +1,171 @@
+package inspect
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+)
+
+// SchemaVersion is the version of the document produced by Inspect. Output
+// from finfo.zsh carries no version field and is treated as version 1.
+const SchemaVersion = 1
+
+// ---------- Document ----------
+
+// Document is the full `finfo --json` schema documented in README.md.
+// Pointer fields are nullable in the JSON output.
+type Document struct {
+    SchemaVersion int      `json:"schema_version,omitempty"`
+    Name          string   `json:"name"`
+    Path          Paths    `json:"path"`
+    IsDir         bool     `json:"is_dir"`
+    Type          Type     `json:"type"`
+    Size          Size     `json:"size"`
+    Lines         *int64   `json:"lines"`
+    Owner         Owner    `json:"owner"`
+    Perms         Perms    `json:"perms"`
+    Dates         Dates    `json:"dates"`
+    Git           Git      `json:"git"`
+    Security      Security `json:"security"`
+    Links         Links    `json:"links"`
+    Symlink       Symlink  `json:"symlink"`
+    Dir           Dir      `json:"dir"`
+    Filetype      Filetype `json:"filetype"`
+    About         string   `json:"about"`
+    Quality       []string `json:"quality"`
+    Actions       []string `json:"actions"`
+    Risk          *Risk    `json:"risk,omitempty"`
+}
+
+type Paths struct {
+    Abs string `json:"abs"`
+    Rel string `json:"rel"`
+}
+
+type Type struct {
+    Description string `json:"description"`
+    IsText      string `json:"is_text"` // text|binary|n/a
+    Charset     string `json:"charset"`
+    Mime        string `json:"mime"`
+}
+
+type Size struct {
+    Bytes int64  `json:"bytes"`
+    Human string `json:"human"`
+}
+
+type Owner struct {
+    User  string `json:"user"`
+    Group string `json:"group"`
+}
+
+type Perms struct {
+    Symbolic string `json:"symbolic"`
+    Octal    string `json:"octal"`
+    Explain  string `json:"explain"`
+}
+
+type Dates struct {
+    Created       string `json:"created"`
+    Modified      string `json:"modified"`
+    Accessed      string `json:"accessed,omitempty"`
+    CreatedEpoch  *int64 `json:"created_epoch"`
+    ModifiedEpoch *int64 `json:"modified_epoch"`
+    AccessedEpoch *int64 `json:"accessed_epoch,omitempty"`
+}
+
+type Git struct {
+    Present bool   `json:"present"`
+    Branch  string `json:"branch"`
+    Status  string `json:"status"` // clean|modified|untracked|added|deleted|changed
+}
+
+type Security struct {
+    Gatekeeper   string   `json:"gatekeeper"` // pass|fail|unknown
+    Codesign     Codesign `json:"codesign"`
+    Notarization string   `json:"notarization"` // ok|missing|unknown
+    Quarantine   string   `json:"quarantine"`   // yes|no
+    WhereFroms   string   `json:"where_froms"`
+    Verdict      string   `json:"verdict"` // safe|caution|unsafe|unknown
+}
+
+type Codesign struct {
+    Signed int    `json:"signed"` // 0|1
+    Status string `json:"status"` // valid|invalid|unknown
+    Team   string `json:"team"`
+}
+
+type Links struct {
+    Hardlinks *int64 `json:"hardlinks"`
+}
+
+type Symlink struct {
+    IsSymlink    int    `json:"is_symlink"`
+    Target       string `json:"target"`
+    TargetExists int    `json:"target_exists"`
+}
+
+type Dir struct {
+    NumDirs   int    `json:"num_dirs"`
+    NumFiles  int    `json:"num_files"`
+    SizeHuman string `json:"size_human"`
+}
+
+type Filetype struct {
+    Pages     *int   `json:"pages"`
+    Headings  *int   `json:"headings"`
+    Columns   *int   `json:"columns"`
+    Delimiter string `json:"delimiter"`
+    ImageDims string `json:"image_dims"`
+}
+
+type Risk struct {
+    Score int    `json:"score"`
+    Why   string `json:"why"`
+}
+
+// ---------- Decoding ----------
+
+// DecodeMode selects how tolerant Decode is.
+type DecodeMode int
+
+const (
+    // Lenient strips noise around the JSON object (stderr lines, xtrace),
+    // ignores unknown fields and fields of the wrong type, and accepts newer
+    // schema versions. This is what the preview pane uses.
+    Lenient DecodeMode = iota
+    // Strict rejects unknown fields, type mismatches, trailing data and
+    // unsupported schema versions. Useful for tooling and goldens.
+    Strict
+)
+
+var ErrNoDocument = errors.New("no JSON object in input")
+
+// Decode parses a `finfo --json` document.
+func Decode(data []byte, mode DecodeMode) (*Document, error) {
+    doc := &Document{}
+    if mode == Strict {
+        dec := json.NewDecoder(bytes.NewReader(data))
+        dec.DisallowUnknownFields()
+        if err := dec.Decode(doc); err != nil { return nil, err }
+        // More() is false before a stray ] or }, so read one more token
+        if _, err := dec.Token(); err != io.EOF { return nil, errors.New("trailing data after document") }
+    } else {
+        i := bytes.IndexByte(data, '{')
+        j := bytes.LastIndexByte(data, '}')
+        if i < 0 || j <= i { return nil, ErrNoDocument }
+        if err := json.Unmarshal(data[i:j+1], doc); err != nil {
+            // Unmarshal still fills every field it can on type errors
+            var te *json.UnmarshalTypeError
+            if !errors.As(err, &te) { return nil, err }
+        }
+    }
+    if doc.SchemaVersion == 0 { doc.SchemaVersion = 1 }
+    if mode == Strict && doc.SchemaVersion > SchemaVersion {
+        return nil, fmt.Errorf("unsupported schema version %d (max %d)", doc.SchemaVersion, SchemaVersion)
+    }
+    if doc.Name == "" { return nil, errors.New("document has no name") }
+    return doc, nil
+}
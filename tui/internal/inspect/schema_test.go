@@ -0,0 +1,50 @@
+package inspect
+
+import "testing"
+
+func TestDecode(t *testing.T) {
+    const doc = `{"schema_version":1,"name":"a.txt","size":{"bytes":3,"human":"3 B"}}`
+    tests := []struct {
+        name   string
+        in     string
+        mode   DecodeMode
+        wantOK bool
+    }{
+        {"strict plain", doc, Strict, true},
+        {"strict trailing newline", doc + "\n", Strict, true},
+        {"strict unknown field", `{"name":"a","bogus":1}`, Strict, false},
+        {"strict trailing object", doc + `{}`, Strict, false},
+        {"strict trailing bracket", doc + `]`, Strict, false},
+        {"strict trailing brace", doc + `}`, Strict, false},
+        {"strict trailing text", doc + ` x`, Strict, false},
+        {"strict type mismatch", `{"name":"a","is_dir":"yes"}`, Strict, false},
+        {"strict newer version", `{"schema_version":99,"name":"a"}`, Strict, false},
+        {"strict no name", `{"size":{"bytes":3}}`, Strict, false},
+        {"lenient unknown field", `{"name":"a","bogus":1}`, Lenient, true},
+        {"lenient noise around", "+ zsh xtrace\n" + doc + "\nwarning: x\n", Lenient, true},
+        {"lenient type mismatch", `{"name":"a","is_dir":"yes"}`, Lenient, true},
+        {"lenient newer version", `{"schema_version":99,"name":"a"}`, Lenient, true},
+        {"lenient no name", `{"size":{"bytes":3}}`, Lenient, false},
+        {"lenient no object", "nothing here", Lenient, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            d, err := Decode([]byte(tt.in), tt.mode)
+            if tt.wantOK {
+                if err != nil || d == nil { t.Fatalf("Decode = %v, %v; want a document", d, err) }
+                if d.Name == "" { t.Errorf("Name is empty") }
+                return
+            }
+            if err == nil { t.Fatalf("Decode succeeded; want an error") }
+            if d != nil { t.Errorf("Decode returned a document with error %v", err) }
+        })
+    }
+}
+
+func TestDecodeFields(t *testing.T) {
+    d, err := Decode([]byte(`{"name":"a.txt","size":{"bytes":3,"human":"3 B"},"lines":null}`), Strict)
+    if err != nil { t.Fatal(err) }
+    if d.SchemaVersion != 1 { t.Errorf("SchemaVersion = %d, want 1 when absent", d.SchemaVersion) }
+    if d.Size.Bytes != 3 || d.Size.Human != "3 B" { t.Errorf("Size = %+v", d.Size) }
+    if d.Lines != nil { t.Errorf("Lines = %v, want nil", *d.Lines) }
+}
@@ -0,0 +1,32 @@
+package inspect
+
+import (
+    "io/fs"
+    "time"
+)
+
+// ---------- Listing helpers ----------
+
+// Stat is the platform stat subset listings need (sorting, detail columns)
+// without building a whole Document.
+type Stat struct {
+    Mode   uint32 // permission and special bits (07777)
+    UID    uint32
+    GID    uint32
+    Nlink  int64
+    Blocks int64 // 512-byte blocks, -1 when unknown
+    ATime  time.Time
+    MTime  time.Time
+    CTime  time.Time // inode change time; zero when unknown
+    BTime  time.Time // birth time; zero when unknown (e.g. Linux without statx)
+}
+
+// StatOf extracts Stat from the result of os.Lstat/os.Stat.
+func StatOf(fi fs.FileInfo) Stat {
+    st := platformStat(fi)
+    return Stat{Mode: st.mode, UID: st.uid, GID: st.gid, Nlink: st.nlink, Blocks: st.blocks, ATime: st.atime, MTime: st.mtime, CTime: st.ctime, BTime: st.btime}
+}
+
+// OwnerNames resolves uid/gid to user and group names (cached), falling back
+// to the numeric ids.
+func OwnerNames(uid, gid uint32) (string, string) { return lookupOwner(uid, gid) }
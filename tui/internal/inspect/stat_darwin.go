@@ -0,0 +1,27 @@
+//go:build darwin
+
+package inspect
+
+import (
+    "io/fs"
+    "syscall"
+    "time"
+)
+
+func platformStat(fi fs.FileInfo) statInfo {
+    st := statInfo{mode: uint32(fi.Mode().Perm()), nlink: 1, blocks: -1, mtime: fi.ModTime()}
+    s, ok := fi.Sys().(*syscall.Stat_t)
+    if !ok { return st }
+    st.mode = uint32(s.Mode) & 07777
+    st.uid, st.gid = s.Uid, s.Gid
+    st.nlink = int64(s.Nlink)
+    st.blocks = s.Blocks
+    st.atime = time.Unix(s.Atimespec.Unix())
+    st.mtime = time.Unix(s.Mtimespec.Unix())
+    st.ctime = time.Unix(s.Ctimespec.Unix())
+    st.btime = time.Unix(s.Birthtimespec.Unix())
+    return st
+}
+
+// formatTime matches `stat -t '%b %d %Y %H:%M'` as used by finfo.zsh on macOS.
+func formatTime(t time.Time) string { return t.Format("Jan 02 2006 15:04") }
@@ -0,0 +1,27 @@
+//go:build linux
+
+package inspect
+
+import (
+    "io/fs"
+    "syscall"
+    "time"
+)
+
+func platformStat(fi fs.FileInfo) statInfo {
+    st := statInfo{mode: uint32(fi.Mode().Perm()), nlink: 1, blocks: -1, mtime: fi.ModTime()}
+    s, ok := fi.Sys().(*syscall.Stat_t)
+    if !ok { return st }
+    st.mode = s.Mode & 07777
+    st.uid, st.gid = s.Uid, s.Gid
+    st.nlink = int64(s.Nlink)
+    st.blocks = s.Blocks
+    st.atime = time.Unix(s.Atim.Unix())
+    st.mtime = time.Unix(s.Mtim.Unix())
+    st.ctime = time.Unix(s.Ctim.Unix())
+    // Birth time needs statx; leave it unknown like `stat -c %w` often does
+    return st
+}
+
+// formatTime matches `stat -c %y` as used by finfo.zsh on Linux.
+func formatTime(t time.Time) string { return t.Format("2006-01-02 15:04:05.000000000 -0700") }
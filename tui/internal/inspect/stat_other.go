@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package inspect
+
+import (
+    "io/fs"
+    "time"
+)
+
+func platformStat(fi fs.FileInfo) statInfo {
+    return statInfo{mode: uint32(fi.Mode().Perm()), nlink: 1, blocks: -1, mtime: fi.ModTime()}
+}
+
+func formatTime(t time.Time) string { return t.Format("2006-01-02 15:04:05") }
@@ -0,0 +1,350 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "time"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Jobs ----------
+
+// Every file operation runs as a job: it waits for one of the pool's
+// workers (FINFOTUI_JOBS, default 4), can be cancelled while queued or
+// running, and stays in the job log (J) with its outcome. The message a
+// job's work returns is handled once the job is recorded as finished.
+
+type jobState int
+
+const (
+    jobQueued jobState = iota
+    jobRunning
+    jobDone
+    jobFailed
+    jobCancelled
+)
+
+var jobStateMarks = [...]string{"…", "▸", "✓", "✗", "⊘"}
+
+type jobFunc func(ctx context.Context, j *job) (tea.Msg, error)
+
+type job struct {
+    id     int
+    act    string
+    target string
+    queued time.Time
+    begun  atomic.Int64 // unix nanos once a worker picks it up
+    end    time.Time
+    state  jobState // final state once end is set
+    err    error
+    n      atomic.Int64 // items done, of total
+    total  atomic.Int64
+    copy   *copyProgress // byte progress of copies
+    notes  []string      // written by the work, read once the job ended
+    fn     jobFunc
+    retry  jobFunc // set by failed work to redo just what failed
+    unrun  func() tea.Msg       // the work's message when cancelled before it started
+    again  func(*model) tea.Cmd // retries through the model instead of rerunning fn
+    ctx    context.Context
+    cancel context.CancelFunc
+}
+
+func (j *job) current() jobState {
+    if !j.end.IsZero() { return j.state }
+    if j.begun.Load() != 0 { return jobRunning }
+    return jobQueued
+}
+
+// progress is the job's running progress, empty when there is nothing to
+// show.
+func (j *job) progress() string {
+    if j.copy != nil { return j.copy.String() }
+    if t := j.total.Load(); t > 1 { return fmt.Sprintf("%s %d/%d", j.act, j.n.Load(), t) }
+    return ""
+}
+
+type jobEndMsg struct {
+    id  int
+    msg tea.Msg
+    err error
+}
+
+const maxJobHistory = 200
+
+type jobManager struct {
+    list   []*job
+    next   int
+    sem    chan struct{}
+    cursor int // job log selection, an index into list
+}
+
+func newJobManager() *jobManager {
+    n := 4
+    if v, err := strconv.Atoi(os.Getenv("FINFOTUI_JOBS")); err == nil && v > 0 { n = v }
+    return &jobManager{sem: make(chan struct{}, n)}
+}
+
+func (jm *jobManager) find(id int) *job {
+    for _, j := range jm.list {
+        if j.id == id { return j }
+    }
+    return nil
+}
+
+func (jm *jobManager) add(act, target string, fn jobFunc) *job {
+    jm.next++
+    ctx, cancel := context.WithCancel(context.Background())
+    j := &job{id: jm.next, act: act, target: target, queued: time.Now(), fn: fn, ctx: ctx, cancel: cancel}
+    jm.list = append(jm.list, j)
+    for len(jm.list) > maxJobHistory {
+        k := 0
+        for k < len(jm.list) && jm.list[k].end.IsZero() { k++ } // the oldest finished one
+        if k == len(jm.list) { break }
+        jm.list = append(jm.list[:k], jm.list[k+1:]...)
+    }
+    if jm.cursor >= len(jm.list) { jm.cursor = len(jm.list) - 1 }
+    return j
+}
+
+// run waits for a worker, then does the job's work. Work cancelled while
+// queued never runs; its unrun hook, if any, still reports back.
+func (jm *jobManager) run(j *job) tea.Cmd {
+    sem := jm.sem
+    return func() tea.Msg {
+        select {
+        case sem <- struct{}{}:
+        case <-j.ctx.Done():
+            var msg tea.Msg
+            if j.unrun != nil { msg = j.unrun() }
+            return jobEndMsg{id: j.id, msg: msg, err: j.ctx.Err()}
+        }
+        defer func() { <-sem }()
+        j.begun.Store(time.Now().UnixNano())
+        msg, err := j.fn(j.ctx, j)
+        return jobEndMsg{id: j.id, msg: msg, err: err}
+    }
+}
+
+func (jm *jobManager) finish(msg jobEndMsg) {
+    j := jm.find(msg.id)
+    if j == nil { return }
+    j.end, j.err = time.Now(), msg.err
+    switch {
+    case errors.Is(msg.err, context.Canceled): j.state = jobCancelled
+    case msg.err != nil: j.state = jobFailed
+    default: j.state = jobDone
+    }
+    j.cancel()
+}
+
+func (jm *jobManager) counts() (running, done, failed int) {
+    for _, j := range jm.list {
+        switch j.current() {
+        case jobQueued, jobRunning: running++
+        case jobDone: done++
+        case jobFailed: failed++
+        }
+    }
+    return
+}
+
+func (jm *jobManager) cancelAll() int {
+    n := 0
+    for _, j := range jm.list {
+        if j.end.IsZero() { j.cancel(); n++ }
+    }
+    return n
+}
+
+// startJob queues work under a label and a target description.
+func (m *model) startJob(act, target string, fn jobFunc) tea.Cmd {
+    j := m.jobs.add(act, target, fn)
+    return m.jobs.run(j)
+}
+
+func (m *model) finishJob(msg jobEndMsg) {
+    m.jobs.finish(msg)
+    j := m.jobs.find(msg.id)
+    if j == nil { return }
+    if j.state == jobDone {
+        if len(j.notes) > 0 { m.status += " (J for details)" }
+        return
+    }
+    if j.state == jobCancelled { m.status = fmt.Sprintf("job %d cancelled", j.id); return }
+    m.status = fmt.Sprintf("job %d failed: %v (J for details)", j.id, j.err)
+}
+
+// retryJob queues a failed or cancelled job again, just its failed part
+// when the work recorded one.
+func (m *model) retryJob(j *job) tea.Cmd {
+    if j.end.IsZero() || j.state == jobDone { return nil }
+    if j.again != nil { return j.again(m) }
+    fn := j.retry
+    if fn == nil { fn = j.fn }
+    return m.startJob(j.act, j.target, fn)
+}
+
+// targetLabel describes the paths a job works on.
+func targetLabel(paths []string) string {
+    if len(paths) == 1 { return filepath.Base(paths[0]) }
+    return fmt.Sprintf("%d items", len(paths))
+}
+
+// ---------- Batches ----------
+
+// batchDoneMsg reports a batch job: n items succeeded and recs are what
+// goes into the undo journal.
+type batchDoneMsg struct {
+    act   action
+    batch int64
+    n     int
+    recs  []journalOp
+    paths []string // targets the work succeeded on
+}
+
+// batchJob runs each over the ops one after another, carrying on past
+// failures; a retry covers just the ops that failed. Notes from each (such
+// as cross-filesystem fallbacks) end up in the job log.
+func batchJob(act action, batch int64, ops []op, each func(context.Context, op) (journalOp, string, error)) jobFunc {
+    return func(ctx context.Context, j *job) (tea.Msg, error) {
+        j.total.Store(int64(len(ops)))
+        msg := batchDoneMsg{act: act, batch: batch}
+        var failed []op
+        var first error
+        for k, o := range ops {
+            if ctx.Err() != nil {
+                failed = append(failed, ops[k:]...)
+                first = ctx.Err()
+                break
+            }
+            rec, note, err := each(ctx, o)
+            if note != "" { j.notes = append(j.notes, note) }
+            if err != nil {
+                j.notes = append(j.notes, err.Error())
+                failed = append(failed, o)
+                if first == nil { first = err }
+                continue
+            }
+            if rec.Kind != "" { msg.recs = append(msg.recs, rec) }
+            msg.paths = append(msg.paths, o.from)
+            msg.n++
+            j.n.Add(1)
+        }
+        if len(failed) > 0 {
+            j.retry = batchJob(act, batch, failed, each)
+            if len(ops) > 1 && !errors.Is(first, context.Canceled) { first = fmt.Errorf("%d of %d failed: %w", len(failed), len(ops), first) }
+        }
+        return msg, first
+    }
+}
+
+// startBatch queues a batch job over ops, journalled as one undo step.
+func (m *model) startBatch(act action, ops []op, each func(context.Context, op) (journalOp, string, error)) tea.Cmd {
+    if len(ops) == 0 { return nil }
+    paths := make([]string, len(ops))
+    for i, o := range ops { paths[i] = o.from }
+    return m.startJob(actionVerbs[act], targetLabel(paths), batchJob(act, m.journal.newBatch(), ops, each))
+}
+
+// actionVerbs name the batch actions in the job log and status line.
+var actionVerbs = map[action]string{actOpen: "open", actReveal: "reveal", actCopy: "copy paths", actClearQ: "clear quarantine", actChmod: "chmod", actChown: "chown", actOpenWith: "open with", actTrash: "trash", actMoveToDir: "move", actRenamePattern: "rename", actEditRename: "rename", actCopyTo: "copy", actLinkTo: "symlink"}
+
+var actionDone = map[action]string{actOpen: "opened", actReveal: "revealed", actCopy: "copied", actClearQ: "quarantine cleared", actChmod: "chmod applied", actChown: "owner changed", actOpenWith: "opened with", actTrash: "trashed", actMoveToDir: "moved", actRenamePattern: "renamed", actEditRename: "renamed", actLinkTo: "linked"}
+
+func (m *model) applyBatchDone(msg batchDoneMsg) tea.Cmd {
+    if name, ok := journalActs[msg.act]; ok {
+        for _, rec := range msg.recs { m.journal.record(msg.batch, name, rec) }
+    }
+    if msg.act == actChmod || msg.act == actChown {
+        // mode and owner changes leave mtime alone: drop the cached previews
+        changed := make([]string, len(msg.recs))
+        for k, rec := range msg.recs { changed[k] = rec.From }
+        m.cache.invalidate(changed)
+    }
+    switch msg.act {
+    case actMoveToDir, actRenamePattern, actEditRename, actLinkTo, actTrash, actChmod, actChown:
+        m.status = fmt.Sprintf("%s %d item(s)", actionDone[msg.act], msg.n)
+        reload := m.reloadList()
+        return tea.Batch(reload, m.refreshPeer())
+    case actClearQ:
+        // the quarantine flag lives in an xattr: drop what the cache holds
+        m.cache.invalidate(msg.paths)
+        m.status = actionDone[msg.act]
+        return m.loadPreview()
+    }
+    m.status = actionDone[msg.act]
+    return nil
+}
+
+// ---------- Job log ----------
+
+// jobsKey drives the job log: ↑/↓ pick a job, x cancels it, X cancels all,
+// r retries a failed or cancelled one.
+func (m *model) jobsKey(k string) tea.Cmd {
+    jm := m.jobs
+    switch k {
+    case "esc", "q", "J": m.mode = modeList
+    case "up", "k": if jm.cursor < len(jm.list)-1 { jm.cursor++ } // newest is shown first
+    case "down", "j": if jm.cursor > 0 { jm.cursor-- }
+    case "x":
+        if j := jm.selected(); j != nil && j.end.IsZero() { j.cancel(); m.status = fmt.Sprintf("cancelling job %d", j.id) }
+    case "X":
+        m.status = fmt.Sprintf("cancelling %d job(s)", jm.cancelAll())
+    case "r":
+        if j := jm.selected(); j != nil {
+            cmd := m.retryJob(j)
+            if cmd != nil { jm.cursor = len(jm.list) - 1; m.status = fmt.Sprintf("retrying job %d", j.id) }
+            return cmd
+        }
+    }
+    return nil
+}
+
+func (jm *jobManager) selected() *job {
+    if jm.cursor < 0 || jm.cursor >= len(jm.list) { return nil }
+    return jm.list[jm.cursor]
+}
+
+// jobsView renders the log newest first, scrolled to keep the selection
+// in view, with the selected job's error and notes below.
+func (m *model) jobsView() string {
+    jm := m.jobs
+    b := &strings.Builder{}
+    running, done, failed := jm.counts()
+    fmt.Fprintf(b, "Jobs: %d running · %d done · %d failed · %d workers\n\n", running, done, failed, cap(jm.sem))
+    if len(jm.list) == 0 { b.WriteString("  no jobs yet\n") }
+    rows := m.height - 16
+    if rows < 5 { rows = 5 }
+    sel := len(jm.list) - 1 - jm.cursor // display position of the selection
+    start := 0
+    if sel >= rows { start = sel - rows + 1 }
+    for pos := start; pos < len(jm.list) && pos < start+rows; pos++ {
+        j := jm.list[len(jm.list)-1-pos]
+        mark := "  "
+        if pos == sel { mark = "› " }
+        st := j.current()
+        when := j.queued.Format("15:04:05")
+        took := ""
+        switch {
+        case !j.end.IsZero() && j.begun.Load() != 0: took = j.end.Sub(time.Unix(0, j.begun.Load())).Round(time.Millisecond).String()
+        case st == jobRunning: took = j.progress()
+        }
+        fmt.Fprintf(b, "%s#%-4d %s %-16s %-24s %s  %s\n", mark, j.id, jobStateMarks[st], j.act, j.target, when, took)
+    }
+    if j := jm.selected(); j != nil && !j.end.IsZero() {
+        if j.err != nil { fmt.Fprintf(b, "\n  error: %v\n", j.err) }
+        for k, n := range j.notes {
+            if k == 8 { fmt.Fprintf(b, "  … %d more\n", len(j.notes)-k); break }
+            fmt.Fprintf(b, "  %s\n", n)
+        }
+    }
+    b.WriteString("\n  ↑/↓ select · x cancel · X cancel all · r retry · esc to close")
+    return b.String()
+}
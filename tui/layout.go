@@ -0,0 +1,240 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/charmbracelet/bubbles/list"
+    tea "github.com/charmbracelet/bubbletea"
+    "github.com/charmbracelet/lipgloss"
+    "github.com/mattn/go-runewidth"
+)
+
+// ---------- Layouts ----------
+
+type layoutKind int
+
+const (
+    layoutSplit  layoutKind = iota // file list | preview
+    layoutMiller                   // parent | current | child listing or preview
+    layoutDual                     // two directory panes (see dual.go)
+    numLayouts
+)
+
+var layoutNames = [numLayouts]string{"split", "miller", "dual"}
+
+// layoutConfig holds the pane proportions in percent of the terminal width:
+// FINFOTUI_LAYOUT=split|miller|dual picks the start layout, FINFOTUI_SPLIT the
+// list share of the split layout (default 45) and FINFOTUI_MILLER the three
+// shares of the Miller layout (default 20,35,45).
+type layoutConfig struct {
+    kind   layoutKind
+    split  int
+    miller [3]int
+}
+
+func layoutFromEnv() layoutConfig {
+    lc := layoutConfig{split: 45, miller: [3]int{20, 35, 45}}
+    for k, name := range layoutNames {
+        if strings.EqualFold(os.Getenv("FINFOTUI_LAYOUT"), name) { lc.kind = layoutKind(k) }
+    }
+    if n, err := strconv.Atoi(os.Getenv("FINFOTUI_SPLIT")); err == nil && n >= 10 && n <= 90 { lc.split = n }
+    if v := os.Getenv("FINFOTUI_MILLER"); v != "" {
+        var p [3]int
+        f := strings.Split(v, ",")
+        ok := len(f) == 3
+        for i := 0; ok && i < 3; i++ {
+            n, err := strconv.Atoi(strings.TrimSpace(f[i]))
+            ok = err == nil && n > 0
+            p[i] = n
+        }
+        if ok { lc.miller = p }
+    }
+    return lc
+}
+
+// applyLayout sizes the panes for the current terminal size and layout.
+func (m *model) applyLayout() {
+    if m.width == 0 { return }
+    if m.singleFile {
+        m.preview.Width = m.width - 2
+        m.preview.Height = m.height - 4
+    } else {
+        h := m.height - 2
+        switch m.layout.kind {
+        case layoutMiller:
+            total := m.layout.miller[0] + m.layout.miller[1] + m.layout.miller[2]
+            avail := m.width - 2 // one-cell gaps between the panes
+            m.sideWidth = avail * m.layout.miller[0] / total
+            lw := avail * m.layout.miller[1] / total
+            if lw < 20 { lw = 20 }
+            m.files.setSize(lw, h)
+            m.preview.Width = avail - m.sideWidth - lw
+        case layoutDual:
+            lw := (m.width - 1) / 2
+            m.files.setSize(lw, h)
+            if i := m.peerTab(); i >= 0 { m.tabs[i].files.setSize(m.width-1-lw, h) }
+            m.preview.Width = m.width - 1 - lw // the preview is not shown, but keep it sized
+        default:
+            lw := m.width * m.layout.split / 100
+            if lw < 30 { lw = 30 }
+            m.files.setSize(lw, h)
+            m.preview.Width = m.width - lw - 1
+        }
+        m.preview.Height = h
+        m.actions.SetSize(m.width/2, m.height/2)
+        m.opsOverlay.Width = m.width - 6
+        m.opsOverlay.Height = m.height - 8
+    }
+    if m.lastDoc != nil {
+        var content string
+        content, m.sections = renderPreview(m.lastDoc, m.long, m.preview.Width, m.theme)
+        m.preview.SetContent(content)
+    }
+}
+
+// cycleLayout switches to the next layout.
+func (m *model) cycleLayout() tea.Cmd {
+    m.layout.kind = (m.layout.kind + 1) % numLayouts
+    m.status = "layout: " + layoutNames[m.layout.kind]
+    m.applyLayout()
+    if m.layout.kind == layoutDual {
+        if m.singleFile { m.layout.kind = layoutSplit; return nil }
+        return m.enterDual()
+    }
+    return m.millerSync()
+}
+
+// ---------- Miller side panes ----------
+
+const sideListMax = 5000 // entries read for a side pane
+
+// sideList is a read-only listing shown beside the current directory.
+type sideList struct {
+    dir       string
+    store     fileStore
+    order     []int32
+    truncated bool
+    readErrs  int
+    err       error
+}
+
+type sideListMsg struct{ l *sideList }
+
+// loadSideList reads dir with the same streaming reader as the main scan,
+// stopping after sideListMax entries.
+func loadSideList(dir string, natural bool) tea.Cmd {
+    return func() tea.Msg {
+        sl := &sideList{dir: dir}
+        ctx, cancel := context.WithCancel(context.Background())
+        defer cancel()
+        sl.err = readDirStream(ctx, dir, func(it fileItem) bool {
+            if sl.store.len() >= sideListMax { sl.truncated = true; cancel(); return false }
+            sl.store.add(it)
+            return true
+        }, &sl.readErrs)
+        if sl.truncated { sl.err = nil }
+        sl.order = make([]int32, sl.store.len())
+        for i := range sl.order { sl.order[i] = int32(i) }
+        less := sortSpec{Natural: natural}.less(&sl.store, false)
+        sort.Slice(sl.order, func(x, y int) bool { return less(sl.order[x], sl.order[y]) })
+        return sideListMsg{l: sl}
+    }
+}
+
+// parentDir is the directory shown in the left Miller pane.
+func (m *model) parentDir() string {
+    if !m.browsing { return "" }
+    abs, err := filepath.Abs(m.cwd)
+    if err != nil || filepath.Dir(abs) == abs { return "" }
+    return filepath.Dir(abs)
+}
+
+// childDir is the directory under the cursor, listed in the right pane.
+func (m *model) childDir() string {
+    it, ok := m.files.selectedItem()
+    if !ok || !it.isDir { return "" }
+    return it.path
+}
+
+// millerSync loads the side listings the Miller layout currently needs.
+func (m *model) millerSync() tea.Cmd {
+    if m.layout.kind != layoutMiller || m.singleFile { return nil }
+    var cmds []tea.Cmd
+    for _, dir := range []string{m.parentDir(), m.childDir()} {
+        if dir == "" { continue }
+        if _, ok := m.sideCache[dir]; ok { continue }
+        if len(m.sideCache) >= 64 {
+            for k, v := range m.sideCache { if v != nil { delete(m.sideCache, k) } }
+        }
+        m.sideCache[dir] = nil // loading
+        cmds = append(cmds, loadSideList(dir, m.sort.Natural))
+    }
+    return tea.Batch(cmds...)
+}
+
+func (m *model) applySideList(msg sideListMsg) {
+    if _, ok := m.sideCache[msg.l.dir]; !ok { return } // dropped by a refresh
+    m.sideCache[msg.l.dir] = msg.l
+}
+
+// millerView renders the three panes; the right one lists the directory
+// under the cursor or shows the preview for files.
+func (m *model) millerView() string {
+    parent := ""
+    focus := ""
+    if abs, err := filepath.Abs(m.cwd); err == nil { focus = abs }
+    if dir := m.parentDir(); dir != "" {
+        parent = renderSideList(m.sideCache[dir], filepath.Base(dir), focus, m.sideWidth, m.files.height, m.files.styles, m.files.titleStyle)
+    } else {
+        parent = renderSideList(nil, "—", "", m.sideWidth, m.files.height, m.files.styles, m.files.titleStyle)
+    }
+    right := ""
+    if dir := m.childDir(); dir != "" {
+        right = renderSideList(m.sideCache[dir], filepath.Base(dir), "", m.preview.Width, m.files.height, m.files.styles, m.files.titleStyle)
+    } else if m.showPreview {
+        right = m.preview.View()
+    }
+    return lipgloss.JoinHorizontal(lipgloss.Top, parent, " ", m.files.View(), " ", right)
+}
+
+// renderSideList draws a compact one-line-per-entry listing, keeping focus
+// (a full path) highlighted and in view.
+func renderSideList(sl *sideList, title, focus string, w, h int, styles list.DefaultItemStyles, titleStyle lipgloss.Style) string {
+    lines := make([]string, 0, h)
+    info := ""
+    switch {
+    case sl == nil && title != "—": info = "loading…"
+    case sl == nil:
+    case sl.err != nil: info = sl.err.Error()
+    case sl.truncated: info = fmt.Sprintf("first %d", sl.store.len())
+    default: info = fmt.Sprintf("%d items", sl.store.len())
+    }
+    if sl != nil && sl.readErrs > 0 { info += fmt.Sprintf(", %d read errors", sl.readErrs) }
+    lines = append(lines, " "+titleStyle.Render(title)+" "+styles.DimmedDesc.Render(info), "")
+    if sl != nil {
+        rows := h - 2
+        at := -1
+        for k, i := range sl.order {
+            if string(sl.store.pathBytes(int(i))) == focus { at = k; break }
+        }
+        start := 0
+        if at >= rows { start = at - rows/2 }
+        for k := start; k < len(sl.order) && len(lines) < h; k++ {
+            i := int(sl.order[k])
+            name := string(sl.store.nameBytes(i))
+            if sl.store.flags[i]&entryDir != 0 { name += "/" }
+            name = runewidth.Truncate(name, w-2, "…")
+            if k == at { name = styles.SelectedTitle.Render(name) } else { name = styles.NormalTitle.Render(name) }
+            lines = append(lines, name)
+        }
+    }
+    for len(lines) < h { lines = append(lines, "") }
+    if h > 0 && len(lines) > h { lines = lines[:h] }
+    return lipgloss.NewStyle().Width(w).MaxWidth(w).Render(strings.Join(lines, "\n"))
+}
@@ -3,15 +3,11 @@ package main
 import (
     "bytes"
     "context"
-    "encoding/json"
-    "errors"
     "fmt"
-    "io/fs"
     "os"
     "os/exec"
     "path/filepath"
     "runtime"
-    "sort"
     "strconv"
     "strings"
     "time"
@@ -24,6 +20,8 @@ import (
     "github.com/charmbracelet/bubbles/viewport"
     tea "github.com/charmbracelet/bubbletea"
     "github.com/charmbracelet/lipgloss"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
 )
 
 // ---------- Files and data ----------
@@ -32,54 +30,20 @@ type fileItem struct{
     path     string
     isDir    bool
     selected bool
+    depth    int    // tree view nesting level
+    note     string // transient render-only annotation (preview state)
 }
 func (i fileItem) Title() string       { return filepath.Base(i.path) }
 func (i fileItem) Description() string {
     prefix := "[ ]"
     if i.selected { prefix = "[x]" }
-    if i.isDir { return prefix + " " + i.path + " — dir" }
-    return prefix + " " + i.path
+    suffix := ""
+    if i.note != "" { suffix = " · " + i.note }
+    if i.isDir { return prefix + " " + i.path + " — dir" + suffix }
+    return prefix + " " + i.path + suffix
 }
 func (i fileItem) FilterValue() string { return i.path }
 
-func collectPaths(args []string, capCount int) ([]fileItem, error) {
-	if len(args) == 0 { args = []string{"."} }
-	items := make([]fileItem, 0, 256)
-	seen := 0
-	push := func(p string, d bool){
-		items = append(items, fileItem{path: p, isDir: d}); seen++
-	}
-	for _, p := range args {
-		fi, err := os.Stat(p)
-		if err != nil { continue }
-		if fi.IsDir() {
-            filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
-				if err != nil { return nil }
-				if d.IsDir() { return nil }
-                push(path, false)
-				if capCount > 0 && seen >= capCount { return errors.New("cap") }
-				return nil
-			})
-		} else {
-			push(p, false)
-		}
-		if capCount > 0 && seen >= capCount { break }
-	}
-	return items, nil
-}
-
-// scanDir lists immediate children of a directory (files and directories)
-func scanDir(dir string) []fileItem {
-    entries, err := os.ReadDir(dir)
-    if err != nil { return nil }
-    items := make([]fileItem, 0, len(entries))
-    for _, e := range entries {
-        p := filepath.Join(dir, e.Name())
-        items = append(items, fileItem{ path: p, isDir: e.IsDir() })
-    }
-    return items
-}
-
 // ---------- Commands ----------
 
 func which(cmd string) string {
@@ -120,6 +84,7 @@ func finfoPrettyArgs(target string, long bool) []string {
 
 func runCmdTimeout(ctx context.Context, name string, args ...string) (string, error) {
 	c := exec.CommandContext(ctx, name, args...)
+	killProcessGroup(c)
 	var out bytes.Buffer
 	c.Stdout = &out
 	c.Stderr = &out
@@ -153,43 +118,36 @@ func clearQuarantine(p string) {
 	if runtime.GOOS == "darwin" && which("xattr") != "" { _ = exec.Command("xattr", "-d", "com.apple.quarantine", p).Run() }
 }
 
-func chmodPath(p, oct string) error {
-	return exec.Command("chmod", oct, p).Run()
-}
-
-func moveToTrash(p string) error {
+// moveToTrash trashes p and returns where it went ("" when Finder does not
+// say). It never deletes: without a usable Trash it fails instead.
+func moveToTrash(ctx context.Context, p string) (string, error) {
     if runtime.GOOS == "darwin" {
         if which("osascript") != "" {
-            // AppleScript move to trash
-            return exec.Command("osascript", "-e", fmt.Sprintf("tell application \"Finder\" to delete POSIX file \"%s\"", p)).Run()
+            // AppleScript move to trash; Finder returns the trashed item
+            q := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(absDir(p))
+            out, err := exec.Command("osascript", "-e", fmt.Sprintf("tell application \"Finder\" to set r to delete (POSIX file \"%s\" as alias)", q), "-e", "return POSIX path of (r as alias)").Output()
+            if err != nil { return "", err }
+            return strings.TrimSuffix(strings.TrimSpace(string(out)), "/"), nil
         }
         // Fallback: move to ~/.Trash (best-effort, no overwrite)
         home, _ := os.UserHomeDir()
-        dst := filepath.Join(home, ".Trash", filepath.Base(p))
-        return os.Rename(p, dst)
+        dst := uniqueDest(filepath.Join(home, ".Trash"), filepath.Base(p))
+        return dst, os.Rename(p, dst)
     }
-    // Linux: try gio or trash-cli if available
-    if which("gio") != "" { return exec.Command("gio", "trash", p).Run() }
-    if which("trash-put") != "" { return exec.Command("trash-put", p).Run() }
-    return os.Remove(p)
+    if runtime.GOOS == "windows" { return "", fmt.Errorf("%s: no Trash support on Windows; not deleting", p) }
+    return trashPut(ctx, p) // freedesktop.org trash, see trash.go
 }
 
 // ---------- JSON preview ----------
 
-type finfoJSON struct {
-    Name string `json:"name"`
-    Path struct{ Abs string `json:"abs"`; Rel string `json:"rel"` } `json:"path"`
-    Size struct{ Bytes int64 `json:"bytes"`; Human string `json:"human"` } `json:"size"`
-    Type struct{ Description string `json:"description"` } `json:"type"`
-    Security struct{ Verdict string `json:"verdict"` } `json:"security"`
-}
+// Preview documents are decoded into inspect.Document (the full `finfo --json`
+// schema); lenient mode tolerates noise and fields from newer finfo versions.
 
 // ---------- UI ----------
 
 type keymap struct {
-    Up, Down, Enter, Back, Quit, ToggleLong, TogglePreview, Actions, Copy, Open, Reveal, Chmod, ClearQ, Refresh, Help, Filter, Select, SelectAll, ClearSel, Undo, JobLog key.Binding
+    Up, Down, Enter, Back, Quit, ToggleLong, TogglePreview, Actions, Copy, Open, Reveal, Chmod, ClearQ, Refresh, Help, Filter, Select, SelectAll, ClearSel, Undo, Redo, JobLog, CancelJobs, Debug, Sort, DetailView, Columns, Tree, TreeOpen, ExpandAll, CollapseAll, Layout, NewTab, CloseTab, NextTab, PrevTab, SendTab, CopyTo, MoveTo, TrashView key.Binding
     PagePrev, PageNext, Jump1, Jump2, Jump3, Jump4, Jump5, Jump6, JumpTop, JumpBottom key.Binding
-    DirPrevPage, DirNextPage key.Binding
 }
 
 // Implement help.KeyMap
@@ -199,11 +157,14 @@ func (k keymap) ShortHelp() []key.Binding {
 
 func (k keymap) FullHelp() [][]key.Binding {
     return [][]key.Binding{
-        {k.Up, k.Down, k.Filter},
+        {k.Up, k.Down, k.PagePrev, k.PageNext, k.JumpTop, k.JumpBottom, k.Filter},
         {k.ToggleLong, k.TogglePreview, k.Open, k.Reveal},
         {k.Chmod, k.ClearQ, k.Refresh},
-        {k.Select, k.SelectAll, k.ClearSel, k.Undo},
-        {k.JobLog, k.Actions, k.Back},
+        {k.Select, k.SelectAll, k.ClearSel, k.Undo, k.Redo, k.Sort, k.DetailView, k.Columns},
+        {k.Tree, k.TreeOpen, k.ExpandAll, k.CollapseAll},
+        {k.NewTab, k.CloseTab, k.NextTab, k.PrevTab, k.SendTab, k.CopyTo, k.MoveTo},
+        {k.JobLog, k.CancelJobs, k.Debug, k.Actions, k.Back, k.Layout, k.TrashView},
+        {k.Jump1, k.Jump2, k.Jump3, k.Jump4, k.Jump5, k.Jump6},
         {k.Help, k.Quit},
     }
 }
@@ -230,19 +191,36 @@ func defaultKeymap() keymap {
         SelectAll:  key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "select all")),
         ClearSel:   key.NewBinding(key.WithKeys("V"), key.WithHelp("V", "clear selection")),
         Undo:       key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "undo last")),
+        Redo:       key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "redo")),
         JobLog:     key.NewBinding(key.WithKeys("J"), key.WithHelp("J", "job log")),
-        PagePrev:   key.NewBinding(key.WithKeys("["), key.WithHelp("[", "prev page")),
-        PageNext:   key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "next page")),
-        DirPrevPage: key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "prev dir page")),
-        DirNextPage: key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "next dir page")),
+        CancelJobs: key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "cancel all jobs")),
+        Debug:      key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "debug overlay")),
+        Sort:       key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort")),
+        DetailView: key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "detail view")),
+        Columns:    key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "columns")),
+        Tree:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "tree view")),
+        TreeOpen:   key.NewBinding(key.WithKeys("right"), key.WithHelp("→", "expand")),
+        ExpandAll:  key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "expand all")),
+        CollapseAll: key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "collapse all")),
+        Layout:     key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "layout")),
+        NewTab:     key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "new tab")),
+        CloseTab:   key.NewBinding(key.WithKeys("ctrl+w"), key.WithHelp("ctrl+w", "close tab")),
+        NextTab:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next tab")),
+        PrevTab:    key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "prev tab")),
+        SendTab:    key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "copy/move to tab")),
+        CopyTo:     key.NewBinding(key.WithKeys("f5"), key.WithHelp("F5", "copy to…")),
+        MoveTo:     key.NewBinding(key.WithKeys("f6"), key.WithHelp("F6", "move to…")),
+        TrashView:  key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "trash")),
+        PagePrev:   key.NewBinding(key.WithKeys("[", "pgup"), key.WithHelp("[/pgup", "prev page")),
+        PageNext:   key.NewBinding(key.WithKeys("]", "pgdown"), key.WithHelp("]/pgdn", "next page")),
         Jump1:      key.NewBinding(key.WithKeys("1"), key.WithHelp("1", "Hdr")),
         Jump2:      key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "Ess")),
         Jump3:      key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "Time")),
         Jump4:      key.NewBinding(key.WithKeys("4"), key.WithHelp("4", "Paths")),
         Jump5:      key.NewBinding(key.WithKeys("5"), key.WithHelp("5", "Sec")),
         Jump6:      key.NewBinding(key.WithKeys("6"), key.WithHelp("6", "Acts")),
-        JumpTop:    key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "top")),
-        JumpBottom: key.NewBinding(key.WithKeys("$"), key.WithHelp("$", "bottom")),
+        JumpTop:    key.NewBinding(key.WithKeys("g", "home"), key.WithHelp("g", "top")),
+        JumpBottom: key.NewBinding(key.WithKeys("$", "G", "end"), key.WithHelp("$/G", "bottom")),
 	}
 }
 
@@ -258,12 +236,17 @@ const (
     modeMoveToDir
     modeRenamePattern
     modeOpsPreview
+    modeFilter
+    modeSort
+    modeColumns
+    modeSendTab
+    modeTrash
+    modeJobs
+    modeChown
 )
 
-type previewMsg struct{ seq int; out string; err string }
-
 type model struct {
-	list    list.Model
+	files   vlist
 	preview viewport.Model
 	help    help.Model
 	keys    keymap
@@ -272,61 +255,83 @@ type model struct {
 	long    bool
 	mode    mode
     actions list.Model
-    jobs    jobs
+    jobs    *jobManager
     spin    spinner.Model
     theme   theme
     originalArgs []string
     lastPreviewRaw string
+    previewEngine  string // engine behind the current preview
+    lastDoc *inspect.Document
+    sections sectionOffsets
     pendingAct action
     pendingOps []op
     pendingDir string
     opsOverlay viewport.Model
     opsOverlayText string
-    jobLog []string
-    showJobLog bool
-    undo []executedOp
+    renameBad int // rows of the rename preview with a problem
+    edit *editRename // rename in $EDITOR, while the editor or its preview is open
+    perm *permEditor // permission editor while open
+    chown *chownPlan // owner change being previewed
+    ownerNames [2][]string // users and groups for completion
+    showDebug bool
+    journal *undoJournal
     // Navigation
     browsing bool
     cwd string
     dirStack []string
     // Preview
     showPreview bool
+    // Tabs
+    tabs []workspace
+    tab int
+    sendMove bool // send-to-tab moves instead of copying
+    peer int // the other pane's tab in the dual layout
+    // Copies
+    copyOpts copyOptions
+    trash *trashView // Trash browser while open
+    // Layout
+    layout layoutConfig
+    width, height int
+    sideWidth int // Miller parent pane
+    sideCache map[string]*sideList // Miller side listings by directory; nil while loading
     // Preview async
+    engine string // "native" (in-process inspector) or "shell" (finfo.zsh --json)
     previewSeq int
+    previewCancel context.CancelFunc
+    previewPath string
+    previewStates map[string]previewState
+    cache *previewCache
+    prefetch *prefetcher
     previewTimeout time.Duration
     previewDelay time.Duration
-    // Large dir management
-    dirAll []fileItem
-    dirCap int
-    listPage int
+    // Streaming scan
+    scan *dirScan
+    scanSeq int
+    scanFocus string
+    scanning bool
+    scanSeen int
+    scanErrs int
+    // Sorting
+    sort sortSpec
+    sortPrefs *sortPrefs
+    statCancel context.CancelFunc
+    // Tree view
+    treeMode bool
+    treeReopen map[string]bool // expanded directories carried over a reload
     // Modes
     singleFile bool
     lastRendered string
 }
 
-type executedOp struct {
-    act action
-    from string
-    to   string
-    reversible bool
-    at time.Time
-}
-
-func (m *model) pushUndo(op executedOp) {
-    m.undo = append(m.undo, op)
-    if len(m.undo) > 100 { m.undo = m.undo[len(m.undo)-100:] }
-}
-
-type jobs struct {
-    running int
-    done    int
-    failed  int
-}
-
 type theme struct {
     title lipgloss.Style
     status lipgloss.Style
     overlay lipgloss.Style
+    // Preview sections (mirror THEME_LABEL/THEME_PATH/THEME_NUM in lib/_colors.zsh)
+    section lipgloss.Style
+    label lipgloss.Style
+    path lipgloss.Style
+    num lipgloss.Style
 }
 
 func defaultTheme() theme {
@@ -334,6 +339,10 @@ func defaultTheme() theme {
         title:  lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true),
         status: lipgloss.NewStyle().Faint(true),
         overlay: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2),
+        section: lipgloss.NewStyle().Foreground(lipgloss.Color("4")).Bold(true),
+        label:  lipgloss.NewStyle().Foreground(lipgloss.Color("5")),
+        path:   lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
+        num:    lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
     }
 }
 
@@ -346,14 +355,24 @@ func themeFromEnv() theme {
         t.title = lipgloss.NewStyle().Bold(true)
         t.status = lipgloss.NewStyle()
         t.overlay = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2)
+        t.section = lipgloss.NewStyle().Bold(true)
+        t.label, t.path, t.num = lipgloss.NewStyle(), lipgloss.NewStyle(), lipgloss.NewStyle()
     case "nord":
         t.title = lipgloss.NewStyle().Foreground(lipgloss.Color("110")).Bold(true)
         t.status = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
         t.overlay = lipgloss.NewStyle().BorderForeground(lipgloss.Color("110")).Border(lipgloss.RoundedBorder()).Padding(1, 2)
+        t.section = lipgloss.NewStyle().Foreground(lipgloss.Color("110")).Bold(true)
+        t.label = lipgloss.NewStyle().Foreground(lipgloss.Color("75"))
+        t.path = lipgloss.NewStyle().Foreground(lipgloss.Color("81"))
+        t.num = lipgloss.NewStyle().Foreground(lipgloss.Color("186"))
     case "dracula":
         t.title = lipgloss.NewStyle().Foreground(lipgloss.Color("171")).Bold(true)
         t.status = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
         t.overlay = lipgloss.NewStyle().BorderForeground(lipgloss.Color("171")).Border(lipgloss.RoundedBorder()).Padding(1, 2)
+        t.section = lipgloss.NewStyle().Foreground(lipgloss.Color("171")).Bold(true)
+        t.label = lipgloss.NewStyle().Foreground(lipgloss.Color("201"))
+        t.path = lipgloss.NewStyle().Foreground(lipgloss.Color("45"))
+        t.num = lipgloss.NewStyle().Foreground(lipgloss.Color("221"))
     }
     return t
 }
@@ -374,6 +393,12 @@ const (
     actMoveToDir
     actRenamePattern
     actUndo
+    actCopyTo
+    actLinkTo
+    actRedo
+    actTrashView
+    actEditRename
+    actChown
 )
 
 type actionItem struct {
@@ -395,35 +420,37 @@ func (m *model) refreshActions() {
         items = append(items, actionItem{name: "Clear quarantine (macOS)", kind: actClearQ})
     }
     items = append(items, actionItem{name: "Change permissions (chmod)", kind: actChmod})
+    items = append(items, actionItem{name: "Change owner (chown)…", kind: actChown})
     // Multi-select and file-only actions
     sel := m.targetItems()
     // Move/Rename only when all selections are files (not dirs)
     allFiles := true
     for _, it := range sel { if it.isDir { allFiles = false; break } }
+    if len(sel) > 0 {
+        items = append(items, actionItem{name: "Copy to directory…", kind: actCopyTo})
+        items = append(items, actionItem{name: "Symlink into directory…", kind: actLinkTo})
+    }
     if len(sel) > 0 && allFiles {
         items = append(items, actionItem{name: "Move to directory…", kind: actMoveToDir})
         items = append(items, actionItem{name: "Rename by pattern…", kind: actRenamePattern})
     }
+    if len(sel) > 0 { items = append(items, actionItem{name: "Rename in editor…", kind: actEditRename}) }
     // Trash allowed for any selection
     if len(sel) > 0 { items = append(items, actionItem{name: "Move to Trash", kind: actTrash}) }
     // Utilities
     items = append(items, actionItem{name: "Copy JSON (preview)", kind: actCopyJSON})
-    if len(m.undo) > 0 { items = append(items, actionItem{name: "Undo last", kind: actUndo}) }
+    items = append(items, actionItem{name: "Browse Trash…", kind: actTrashView})
+    if u := m.journal.peek(false); u != "" { items = append(items, actionItem{name: "Undo last (" + u + ")", kind: actUndo}) }
+    if r := m.journal.peek(true); r != "" { items = append(items, actionItem{name: "Redo (" + r + ")", kind: actRedo}) }
     m.actions.SetItems(items)
 }
 
 type op struct{ from, to string }
 
 func initialModelFromArgs(args []string) model {
-    items, _ := collectPaths(args, 5000)
-    l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
-	li := make([]list.Item, len(items))
-	for i := range items { li[i] = items[i] }
-	l.SetItems(li)
-	l.Title = "Files"
-	l.SetShowStatusBar(false)
-	l.SetShowHelp(false)
-	l.SetFilteringEnabled(true)
+    states := make(map[string]previewState, 64)
+    fl := newVList(states)
+    fl.cols, fl.detail = detailConfigFromEnv()
     pv := viewport.Model{ Width: 0, Height: 0 }
 	pv.YPosition = 0
     in := textinput.New(); in.Placeholder = "filter"; in.Prompt = "/ "; in.CharLimit = 256; in.Blur()
@@ -440,170 +467,91 @@ func initialModelFromArgs(args []string) model {
     if v := os.Getenv("FINFOTUI_PREVIEW_DELAY_MS"); v != "" {
         if n, err := strconv.Atoi(v); err == nil && n >= 0 { delayMs = n }
     }
-    m := model{ list: l, preview: pv, help: help.New(), keys: defaultKeymap(), filter: in, long: true, mode: modeList, actions: acts, spin: sp, theme: th, originalArgs: append([]string{}, args...), opsOverlay: ov, showPreview: true, previewTimeout: time.Duration(timeoutMs) * time.Millisecond, previewDelay: time.Duration(delayMs) * time.Millisecond, dirCap: 5000 }
+    engine := "native"
+    if v := strings.ToLower(os.Getenv("FINFOTUI_ENGINE")); v == "shell" { engine = v }
+    cache := previewCacheFromEnv()
+    m := model{ files: fl, preview: pv, help: help.New(), keys: defaultKeymap(), filter: in, long: true, mode: modeList, actions: acts, spin: sp, theme: th, originalArgs: append([]string{}, args...), opsOverlay: ov, showPreview: true, engine: engine, sections: noSections(), previewStates: states, cache: cache, prefetch: prefetcherFromEnv(cache, engine, time.Duration(timeoutMs) * time.Millisecond), sortPrefs: loadSortPrefs(), journal: loadUndoJournal(), jobs: newJobManager(), layout: layoutFromEnv(), tabs: make([]workspace, 1), sideCache: map[string]*sideList{}, previewTimeout: time.Duration(timeoutMs) * time.Millisecond, previewDelay: time.Duration(delayMs) * time.Millisecond }
     // Enable directory-browsing mode when a single argument is a directory
     if len(args) == 1 {
         if fi, err := os.Stat(args[0]); err == nil && fi.IsDir() {
             m.browsing = true
             m.cwd = args[0]
-            items := scanDir(m.cwd)
-            sort.Slice(items, func(i,j int) bool { if items[i].isDir != items[j].isDir { return items[i].isDir } ; return strings.ToLower(filepath.Base(items[i].path)) < strings.ToLower(filepath.Base(items[j].path)) })
-            m.dirAll = items
-            m.listPage = 0
-            m.rebuildDirPage()
         } else if err == nil && !fi.IsDir() {
             m.singleFile = true
             // Seed list with the file so preview can load immediately
-            l.SetItems([]list.Item{fileItem{path: args[0], isDir: false}})
+            m.files.setItems([]fileItem{{path: args[0]}})
         }
     }
+    // The listing streams in via Init; the channel is created here because
+    // Init cannot store it on the model.
+    if !m.singleFile {
+        if m.browsing { m.sort, _ = m.sortPrefs.get(m.cwd) }
+        m.files.sortInfo = m.sort.String()
+        m.scanSeq++
+        m.scanning = true
+        m.scan = startScan(m.scanSeq, m.browsing, m.cwd, m.originalArgs, nil)
+    }
     return m
 }
 
 func (m model) Init() tea.Cmd {
-    // When single-file, seed preview immediately even if list is empty
-    if m.singleFile && len(m.list.Items()) == 0 && len(m.originalArgs) == 1 {
-        // create a synthetic list with the file so preview works
-        if fi, err := os.Stat(m.originalArgs[0]); err == nil {
-            it := fileItem{path: m.originalArgs[0], isDir: fi.IsDir()}
-            m.list.SetItems([]list.Item{it})
-        }
-    }
+    // Init cannot mutate the model, so kick off the first preview via a tick
+    // matching the initial sequence number.
     if m.showPreview { m.preview.SetContent("Loading…") }
-    return tea.Batch(m.loadPreview(), m.spin.Tick)
-}
-
-func (m model) loadPreview() tea.Cmd {
-    if !m.showPreview || len(m.list.Items()) == 0 { return nil }
-	it, ok := m.list.SelectedItem().(fileItem)
-	if !ok { return nil }
-    // No explicit cancel func retained; sequence guard prevents stale updates
-    args := finfoPreviewArgs(it.path, m.long)
-    seq := m.previewSeq + 1
-    m.previewSeq = seq
-	return func() tea.Msg {
-        ctx, cancel := context.WithTimeout(context.Background(), m.previewTimeout)
-        // store cancel so next call can cancel in-flight
-        // Note: model is captured immutably; we pass seq to validate latest
-        out, err := runCmdTimeout(ctx, args[0], args[1:]...)
-        cancel()
-        emsg := ""
-        if err != nil { emsg = err.Error() }
-        return previewMsg{seq: seq, out: out, err: emsg}
-	}
+    seq := m.previewSeq
+    cmds := []tea.Cmd{func() tea.Msg { return previewTickMsg{seq: seq} }, m.spin.Tick}
+    if m.scan != nil { cmds = append(cmds, waitScan(m.scan)) }
+    if peer := m.refreshPeer(); peer != nil { cmds = append(cmds, peer) }
+    return tea.Batch(cmds...)
 }
 
-func (m model) reloadList() tea.Cmd {
+// reloadList rescans the current listing, keeping selection marks and the
+// cursor position.
+func (m *model) reloadList() tea.Cmd {
     prevSel := make(map[string]bool, 32)
-    for i := 0; i < len(m.list.Items()); i++ {
-        if it, ok := m.list.Items()[i].(fileItem); ok && it.selected { prevSel[it.path] = true }
-    }
-    if m.browsing {
-        cwd := m.cwd
-        return func() tea.Msg {
-            items := scanDir(cwd)
-            sort.Slice(items, func(i,j int) bool { if items[i].isDir != items[j].isDir { return items[i].isDir } ; return strings.ToLower(filepath.Base(items[i].path)) < strings.ToLower(filepath.Base(items[j].path)) })
-            for i := range items { items[i].selected = prevSel[items[i].path] }
-            return listDirMsg{items: items}
-        }
-    }
-    args := m.originalArgs
-    return func() tea.Msg {
-        items, _ := collectPaths(args, 5000)
-        li := make([]list.Item, len(items))
-        for i := range items { items[i].selected = prevSel[items[i].path]; li[i] = items[i] }
-        // Return a closure to update list on main thread
-        return func() tea.Msg { return listMsg{items: li} }
-    }
-}
-
-type listMsg struct{ items []list.Item }
-type listDirMsg struct{ items []fileItem }
-
-func (m *model) rebuildDirPage() {
-    if !m.browsing { return }
-    total := len(m.dirAll)
-    if m.dirCap <= 0 { m.dirCap = 2000 }
-    pages := (total + m.dirCap - 1) / m.dirCap
-    if pages < 1 { pages = 1 }
-    if m.listPage >= pages { m.listPage = pages - 1 }
-    if m.listPage < 0 { m.listPage = 0 }
-    start := m.listPage * m.dirCap
-    end := start + m.dirCap
-    if end > total { end = total }
-    window := m.dirAll[start:end]
-    li := make([]list.Item, len(window))
-    for i := range window { li[i] = window[i] }
-    m.list.SetItems(li)
+    for _, it := range m.files.selectedItems() { prevSel[it.path] = true }
+    focus := ""
+    if it, ok := m.files.selectedItem(); ok { focus = it.path }
+    m.treeReopen = m.files.openPaths()
+    m.sideCache = map[string]*sideList{}
+    return m.beginScan(focus, prevSel)
 }
 
 // Helper: determine target items (selected ones if any, otherwise current)
 func (m model) targetItems() []fileItem {
-    selected := make([]fileItem, 0, 8)
-    for i := 0; i < len(m.list.Items()); i++ {
-        if it, ok := m.list.Items()[i].(fileItem); ok && it.selected { selected = append(selected, it) }
-    }
-    if len(selected) > 0 { return selected }
-    if it, ok := m.list.SelectedItem().(fileItem); ok { return []fileItem{it} }
+    if selected := m.files.selectedItems(); len(selected) > 0 { return selected }
+    if it, ok := m.files.selectedItem(); ok { return []fileItem{it} }
     return nil
 }
 
-// Jobs messages
-type jobDoneMsg struct{ path string; act action; err error }
-
+// runActionOnTargets queues act over the targets as one batch job.
 func (m model) runActionOnTargets(act action) tea.Cmd {
     targets := m.targetItems()
     if len(targets) == 0 { return nil }
-    cmds := make([]tea.Cmd, 0, len(targets))
-    mstatus := ""
+    ops := make([]op, len(targets))
+    for i, t := range targets { ops[i] = op{from: t.path} }
     switch act {
     case actCopy:
         // Copy all paths as newline-joined (single job)
-        paths := make([]string, 0, len(targets))
-        for _, t := range targets { paths = append(paths, t.path) }
-        return func() tea.Msg {
+        paths := make([]string, len(targets))
+        for i, t := range targets { paths[i] = t.path }
+        return m.startJob(actionVerbs[act], targetLabel(paths), func(context.Context, *job) (tea.Msg, error) {
             copyPathsJoined(paths)
-            return jobDoneMsg{path: strings.Join(paths, ", "), act: act, err: nil}
-        }
+            return batchDoneMsg{act: act, n: len(paths)}, nil
+        })
     case actOpen:
-        for _, t := range targets {
-            p := t.path
-            cmds = append(cmds, func() tea.Msg {
-                openPath(p)
-                return jobDoneMsg{path: p, act: act, err: nil}
-            })
-        }
-        mstatus = "opening"
+        return m.startBatch(act, ops, func(_ context.Context, o op) (journalOp, string, error) { openPath(o.from); return journalOp{}, "", nil })
     case actReveal:
-        for _, t := range targets {
-            p := t.path
-            cmds = append(cmds, func() tea.Msg {
-                revealPath(p)
-                return jobDoneMsg{path: p, act: act, err: nil}
-            })
-        }
-        mstatus = "revealing"
+        return m.startBatch(act, ops, func(_ context.Context, o op) (journalOp, string, error) { revealPath(o.from); return journalOp{}, "", nil })
     case actClearQ:
-        for _, t := range targets {
-            p := t.path
-            cmds = append(cmds, func() tea.Msg {
-                clearQuarantine(p)
-                return jobDoneMsg{path: p, act: act, err: nil}
-            })
-        }
-        mstatus = "clearing quarantine"
+        return m.startBatch(act, ops, func(_ context.Context, o op) (journalOp, string, error) { clearQuarantine(o.from); return journalOp{}, "", nil })
     case actTrash:
-        for _, t := range targets {
-            p := t.path
-            cmds = append(cmds, func() tea.Msg {
-                err := moveToTrash(p)
-                return jobDoneMsg{path: p, act: act, err: err}
-            })
-        }
-        mstatus = "trashing"
+        return m.startBatch(act, ops, func(ctx context.Context, o op) (journalOp, string, error) {
+            loc, err := moveToTrash(ctx, o.from)
+            return journalOp{Kind: "trash", From: o.from, To: loc}, "", err
+        })
     }
-    if mstatus != "" { /* no-op, status updated by caller */ }
-    return tea.Batch(cmds...)
+    return nil
 }
 
 func copyPathsJoined(paths []string) {
@@ -615,20 +563,6 @@ func copyPathsJoined(paths []string) {
     if which("xclip") != "" { _ = exec.Command("sh", "-c", fmt.Sprintf("printf '%%s' %q | xclip -selection clipboard", joined)).Run(); return }
 }
 
-// Undo execution
-func (m model) runUndo() tea.Cmd {
-    if len(m.undo) == 0 { m.status = "nothing to undo"; return nil }
-    last := m.undo[len(m.undo)-1]
-    m.undo = m.undo[:len(m.undo)-1]
-    if !last.reversible { m.status = "cannot undo"; return nil }
-    from := last.from; to := last.to
-    m.jobs.running++
-    return func() tea.Msg {
-        err := os.Rename(from, to)
-        return jobDoneMsg{path: from + " -> " + to, act: actUndo, err: err}
-    }
-}
-
 func openWithPath(app, p string) {
     if runtime.GOOS == "darwin" {
         _ = exec.Command("open", "-a", app, p).Start()
@@ -646,84 +580,109 @@ func copyText(s string) {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+    tm, cmd := m.update(msg)
+    mm, ok := tm.(model)
+    if !ok { return tm, cmd }
+    // whatever moved the window, the detail view needs stat data for it
+    if stat := mm.statVisible(); stat != nil { cmd = tea.Batch(cmd, stat) }
+    if usage := mm.treeUsageVisible(); usage != nil { cmd = tea.Batch(cmd, usage) }
+    if side := mm.millerSync(); side != nil { cmd = tea.Batch(cmd, side) }
+    return mm, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		// Layout: left list 40%, right preview 60%
-        if m.singleFile {
-            m.preview.Width = msg.Width - 2
-            m.preview.Height = msg.Height - 4
-            return m, nil
-        }
-        lw := int(float64(msg.Width) * 0.45)
-		if lw < 30 { lw = 30 }
-        pw := msg.Width - lw - 1
-		lh := msg.Height - 2
-		ph := msg.Height - 2
-		m.list.SetSize(lw, lh)
-        m.preview.Width = pw; m.preview.Height = ph
-        m.actions.SetSize(msg.Width/2, msg.Height/2)
-        m.opsOverlay.Width = msg.Width - 6
-        m.opsOverlay.Height = msg.Height - 8
+        m.width, m.height = msg.Width, msg.Height
+        m.applyLayout()
+        return m, nil
     case spinner.TickMsg:
         var cmd tea.Cmd
         m.spin, cmd = m.spin.Update(msg)
         return m, cmd
-    case previewMsg:
+    case previewTickMsg:
         if msg.seq != m.previewSeq { return m, nil }
-        // If debounce tick (empty out), now run the real preview load
-        if msg.out == "" { return m, m.loadPreview() }
-        // Try parse JSON, fallback to raw text
-        var fj finfoJSON
-        s := msg.out
-        // Extract JSON object if stderr/noise appended
-        if i := strings.Index(s, "{"); i >= 0 {
-            if j := strings.LastIndex(s, "}"); j > i { s = s[i:j+1] }
-        }
-        m.lastPreviewRaw = s
-        if err := json.Unmarshal([]byte(s), &fj); err == nil && fj.Name != "" {
-            b := &strings.Builder{}
-            fmt.Fprintf(b, "%s\n", lipgloss.NewStyle().Bold(true).Render(fj.Name))
-            fmt.Fprintf(b, "Type: %s\n", fj.Type.Description)
-            fmt.Fprintf(b, "Size: %s (%d B)\n", fj.Size.Human, fj.Size.Bytes)
-            if fj.Security.Verdict != "" { fmt.Fprintf(b, "Verdict: %s\n", fj.Security.Verdict) }
-            fmt.Fprintf(b, "Rel: %s\nAbs: %s\n", fj.Path.Rel, fj.Path.Abs)
-            m.preview.SetContent(b.String())
-        } else {
-            // If JSON failed, try pretty output for a faithful static preview
-            it, _ := m.list.SelectedItem().(fileItem)
-            args := finfoPrettyArgs(it.path, m.long)
-            ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second); defer cancel()
-            out, _ := runCmdTimeout(ctx, args[0], args[1:]...)
-            if strings.TrimSpace(out) != "" { m.preview.SetContent(out) } else { m.preview.SetContent(s) }
-        }
-        if msg.err != "" {
-            m.status = "preview error: " + msg.err
-        } else {
-            m.status = ""
-        }
-    case listMsg:
-        m.list.SetItems(msg.items)
-        return m, m.loadPreview()
-    case jobDoneMsg:
-        m.jobs.running--
-        if msg.err != nil { m.jobs.failed++ } else { m.jobs.done++ }
-        switch msg.act {
-        case actCopy: m.status = "copied" 
-        case actOpen: m.status = "opened" 
-        case actReveal: m.status = "revealed"
-        case actClearQ: m.status = "quarantine cleared"
-        case actMoveToDir: m.status = "moved"
-        case actRenamePattern: m.status = "renamed"
-        case actTrash: m.status = "trashed"
-        case actUndo: m.status = "undone"
-        }
+        cmd := m.startPreview(msg.seq)
+        return m, cmd
+    case previewMsg:
+        m.applyPreview(msg)
+        return m, nil
+    case scanChunkMsg:
+        cmd := m.applyScan(msg)
+        return m, cmd
+    case statMsg:
+        cmd := m.applyStat(msg)
+        return m, cmd
+    case gitStatusMsg:
+        m.applyGitStatus(msg)
         return m, nil
+    case treeKidsMsg:
+        cmd := m.applyTreeKids(msg)
+        return m, cmd
+    case treeUsageMsg:
+        m.applyTreeUsage(msg)
+        return m, nil
+    case sideListMsg:
+        m.applySideList(msg)
+        return m, nil
+    case peerListMsg:
+        m.applyPeerList(msg)
+        return m, nil
+    case undoDoneMsg:
+        cmd := m.applyUndoDone(msg)
+        return m, cmd
+    case trashListMsg:
+        m.applyTrashList(msg)
+        return m, nil
+    case trashDoneMsg:
+        cmd := m.applyTrashDone(msg)
+        return m, cmd
+    case copyDoneMsg:
+        cmd := m.applyCopyDone(msg)
+        return m, cmd
+    case editDoneMsg:
+        m.applyEditDone(msg)
+        return m, nil
+    case batchDoneMsg:
+        cmd := m.applyBatchDone(msg)
+        return m, cmd
+    case jobEndMsg:
+        // The work's own message first, so a failure still records what
+        // succeeded and the job's outcome has the last word on the status.
+        var cmd tea.Cmd
+        if msg.msg != nil { tm, c := m.update(msg.msg); m = tm.(model); cmd = c }
+        m.finishJob(msg)
+        return m, cmd
 	case tea.KeyMsg:
         // Global toggle for help overlay
         if key.Matches(msg, m.keys.Help) {
             if m.mode == modeHelp { m.mode = modeList } else { m.mode = modeHelp }
             return m, nil
         }
+        if m.mode == modeSort {
+            cmd := m.sortMenuKey(msg.String())
+            return m, cmd
+        }
+        if m.mode == modeColumns {
+            cmd := m.columnsMenuKey(msg.String())
+            return m, cmd
+        }
+        if m.mode == modeSendTab {
+            cmd := m.sendMenuKey(msg.String())
+            return m, cmd
+        }
+        if m.mode == modeTrash {
+            cmd := m.trashKey(msg.String())
+            return m, cmd
+        }
+        if m.mode == modeJobs {
+            cmd := m.jobsKey(msg.String())
+            return m, cmd
+        }
+        if m.mode == modeChmod {
+            cmd := m.permKey(msg)
+            return m, cmd
+        }
         if m.mode == modeHelp {
             if msg.Type == tea.KeyEsc || msg.String() == "q" || msg.String() == "?" {
                 m.mode = modeList
@@ -745,7 +704,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
                         m.status = fmt.Sprintf("confirm clear quarantine for %d item(s)? y/N", len(m.targetItems()))
                         return m, nil
                     case actChmod:
-                        m.mode = modeChmod; m.filter.Placeholder = "octal (e.g. 644)"; m.filter.SetValue(""); m.filter.Focus(); return m, nil
+                        m.mode = modeList
+                        m.openPerms()
+                        return m, nil
+                    case actChown:
+                        m.chown = nil
+                        m.openChown()
+                        return m, nil
                     case actOpenWith:
                         m.mode = modeOpenWith; m.filter.Placeholder = "app name (e.g. Preview)"; m.filter.SetValue(""); m.filter.Focus(); return m, nil
                     case actCopyJSON:
@@ -756,15 +721,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
                         m.pendingAct = actTrash
                         m.status = fmt.Sprintf("confirm move to Trash for %d item(s)? y/N", len(m.targetItems()))
                         return m, nil
-                    case actMoveToDir:
-                        m.mode = modeMoveToDir; m.filter.Placeholder = "destination directory"; m.filter.SetValue(""); m.filter.Focus(); return m, nil
+                    case actMoveToDir, actCopyTo, actLinkTo:
+                        m.transferInput(it.kind); return m, nil
                     case actRenamePattern:
-                        m.mode = modeRenamePattern; m.filter.Placeholder = "pattern: {name}{ext} or {name}-{n}{ext}"; m.filter.SetValue("{name}{ext}"); m.filter.Focus(); return m, nil
-                    case actUndo:
-                        // Trigger undo via keybinding or action
-                        return m, m.runUndo()
+                        m.mode = modeRenamePattern; m.filter.Placeholder = "pattern: {name}-{n:3}{ext}, {date:YYYYMMDD}_{name|lower}{ext} or /regex/{1}"; m.filter.SetValue("{name}{ext}"); m.filter.Focus(); return m, nil
+                    case actTrashView:
+                        cmd := m.openTrash()
+                        return m, cmd
+                    case actEditRename:
+                        m.mode = modeList
+                        cmd := m.startEditRename()
+                        return m, cmd
+                    case actUndo, actRedo:
+                        m.mode = modeList
+                        cmd := m.runUndo(it.kind == actRedo)
+                        return m, cmd
                     default:
-                        m.jobs.running += len(m.targetItems())
                         return m, m.runActionOnTargets(it.kind)
                     }
                 }
@@ -780,38 +752,69 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
             case tea.KeyEsc:
                 m.mode = modeList
                 m.pendingAct = 0; m.pendingOps = nil
+                m.endEditRename()
+                m.chown = nil
                 return m, nil
             case tea.KeyEnter:
                 // Confirm and execute
-                if m.pendingAct == actMoveToDir || m.pendingAct == actRenamePattern {
+                if (m.pendingAct == actRenamePattern || m.pendingAct == actEditRename) && m.renameBad > 0 {
+                    m.status = fmt.Sprintf("fix %d problem(s) first (e to edit again)", m.renameBad)
+                    return m, nil
+                }
+                if m.pendingAct == actChown {
+                    m.mode = modeList
+                    cmd := m.startChown()
+                    return m, cmd
+                }
+                if m.pendingAct == actCopyTo {
+                    cmd := m.startCopy(m.pendingOps)
+                    m.pendingOps = nil; m.pendingAct = 0
+                    m.mode = modeList
+                    return m, cmd
+                }
+                if act := m.pendingAct; act == actMoveToDir || act == actRenamePattern || act == actEditRename {
                     ops := m.pendingOps
-                    cmds := make([]tea.Cmd, 0, len(ops))
-                    for _, op := range ops { from := op.from; to := op.to; cmds = append(cmds, func() tea.Msg { err := os.Rename(from, to); return jobDoneMsg{path: from + " -> " + to, act: m.pendingAct, err: err} }) }
-                    m.jobs.running += len(ops)
                     m.pendingOps = nil; m.pendingAct = 0
                     m.mode = modeList
-                    return m, tea.Batch(tea.Batch(cmds...), m.reloadList())
+                    m.endEditRename()
+                    cmd := m.startRenames(act, ops)
+                    return m, cmd
+                }
+                if m.pendingAct == actLinkTo {
+                    ops := m.pendingOps
+                    m.pendingOps = nil; m.pendingAct = 0
+                    m.mode = modeList
+                    cmd := m.startBatch(actLinkTo, ops, func(_ context.Context, o op) (journalOp, string, error) { return journalOp{Kind: "link", From: o.from, To: o.to}, "", symlinkEntry(o.from, o.to) })
+                    return m, cmd
                 }
                 return m, nil
+            default:
+                if m.pendingAct == actCopyTo && m.copyPreviewKey(msg.String()) { return m, nil }
+                if m.pendingAct == actChown && m.chownPreviewKey(msg.String()) { return m, nil }
+                if m.pendingAct == actRenamePattern && msg.String() == "e" {
+                    m.mode = modeRenamePattern; m.pendingOps = nil; m.filter.Focus()
+                    return m, nil
+                }
+                if m.pendingAct == actEditRename && msg.String() == "e" && m.edit != nil {
+                    m.mode = modeList; m.pendingOps = nil; m.pendingAct = 0
+                    return m, m.runEditor()
+                }
             }
         }
         if m.mode == modeConfirm {
             s := msg.String()
             if s == "y" || s == "Y" {
-                m.jobs.running += len(m.targetItems())
                 m.mode = modeList
                 if m.pendingAct == actTrash {
                     m.pendingAct = 0
-                    return m, tea.Batch(m.runActionOnTargets(actTrash), m.reloadList())
-            } else if m.pendingAct == actMoveToDir || m.pendingAct == actRenamePattern {
-                ops := m.pendingOps
-                // Run file operations
-                cmds := make([]tea.Cmd, 0, len(ops))
-                for _, op := range ops { from := op.from; to := op.to; cmds = append(cmds, func() tea.Msg { err := os.Rename(from, to); return jobDoneMsg{path: from + " -> " + to, act: m.pendingAct, err: err} }) }
-                m.pendingOps = nil; m.pendingAct = 0
-                return m, tea.Batch(tea.Batch(cmds...), m.reloadList())
+                    return m, m.runActionOnTargets(actTrash)
+                } else if m.pendingAct == actMoveToDir || m.pendingAct == actRenamePattern {
+                    ops, act := m.pendingOps, m.pendingAct
+                    m.pendingOps = nil; m.pendingAct = 0
+                    cmd := m.startRenames(act, ops)
+                    return m, cmd
                 }
-                return m, tea.Batch(m.runActionOnTargets(actClearQ), m.loadPreview())
+                return m, m.runActionOnTargets(actClearQ)
             }
             if s == "n" || s == "N" || msg.Type == tea.KeyEsc {
                 m.mode = modeList
@@ -819,7 +822,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
             }
             return m, nil
         }
+        // Text input modes receive every key
+        if m.filter.Focused() { break }
 		switch {
+		case msg.Type == tea.KeyEsc && m.files.query != "":
+            m.files.setFilter("")
+            cmd := m.schedulePreview()
+            return m, cmd
 		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
         case key.Matches(msg, m.keys.Up), key.Matches(msg, m.keys.Down):
@@ -828,28 +837,47 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
                 m.preview, cmd = m.preview.Update(msg)
                 return m, cmd
             }
-            var cmd tea.Cmd
-            m.list, cmd = m.list.Update(msg)
-            // debounce preview to avoid thrash when scrolling
-            seq := m.previewSeq + 1
-            m.previewSeq = seq
-            delayed := tea.Tick(m.previewDelay, func(time.Time) tea.Msg { return previewMsg{seq: seq} })
-            return m, tea.Batch(cmd, delayed)
+            if key.Matches(msg, m.keys.Up) { m.files.move(-1) } else { m.files.move(1) }
+            // debounce preview to avoid thrash when scrolling; cancels the in-flight one
+            delayed := m.schedulePreview()
+            m.prefetchAround()
+            return m, delayed
         case key.Matches(msg, m.keys.ToggleLong):
 			m.long = !m.long
-			return m, m.loadPreview()
+			cmd := m.loadPreview()
+			return m, cmd
         case key.Matches(msg, m.keys.TogglePreview):
             m.showPreview = !m.showPreview
-            return m, m.loadPreview()
+            if !m.showPreview { m.cancelPreview() }
+            cmd := m.loadPreview()
+            return m, cmd
         // Single-file preview navigation shortcuts
-        case key.Matches(msg, m.keys.PagePrev):
-            if m.singleFile { m.preview.LineUp(10); return m, nil }
-        case key.Matches(msg, m.keys.PageNext):
-            if m.singleFile { m.preview.LineDown(10); return m, nil }
-        case key.Matches(msg, m.keys.JumpTop):
-            if m.singleFile { m.preview.GotoTop(); return m, nil }
-        case key.Matches(msg, m.keys.JumpBottom):
-            if m.singleFile { m.preview.GotoBottom(); return m, nil }
+        case key.Matches(msg, m.keys.PagePrev), key.Matches(msg, m.keys.PageNext), key.Matches(msg, m.keys.JumpTop), key.Matches(msg, m.keys.JumpBottom):
+            if m.singleFile {
+                switch {
+                case key.Matches(msg, m.keys.PagePrev): m.preview.LineUp(10)
+                case key.Matches(msg, m.keys.PageNext): m.preview.LineDown(10)
+                case key.Matches(msg, m.keys.JumpTop): m.preview.GotoTop()
+                default: m.preview.GotoBottom()
+                }
+                return m, nil
+            }
+            switch {
+            case key.Matches(msg, m.keys.PagePrev): m.files.pageUp()
+            case key.Matches(msg, m.keys.PageNext): m.files.pageDown()
+            case key.Matches(msg, m.keys.JumpTop): m.files.top()
+            default: m.files.bottom()
+            }
+            delayed := m.schedulePreview()
+            m.prefetchAround()
+            return m, delayed
+        // Section jumps work in split and single-file modes
+        case key.Matches(msg, m.keys.Jump1): m.jumpToSection(secHeader); return m, nil
+        case key.Matches(msg, m.keys.Jump2): m.jumpToSection(secEssentials); return m, nil
+        case key.Matches(msg, m.keys.Jump3): m.jumpToSection(secTimeline); return m, nil
+        case key.Matches(msg, m.keys.Jump4): m.jumpToSection(secPaths); return m, nil
+        case key.Matches(msg, m.keys.Jump5): m.jumpToSection(secSecurity); return m, nil
+        case key.Matches(msg, m.keys.Jump6): m.jumpToSection(secActions); return m, nil
 		case key.Matches(msg, m.keys.Copy):
             targets := m.targetItems()
             if len(targets) == 1 { copyPath(targets[0].path) } else {
@@ -857,100 +885,172 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
                 copyPathsJoined(paths)
             }
             m.status = "copied"
-        case key.Matches(msg, m.keys.Enter):
+        case key.Matches(msg, m.keys.Enter) && m.files.tree != nil:
+            // the tree expands in place instead of descending
+            cmd := m.treeToggleAt()
+            return m, cmd
+        case key.Matches(msg, m.keys.Enter), m.layout.kind == layoutMiller && m.files.tree == nil && key.Matches(msg, m.keys.TreeOpen):
 			if m.browsing {
-				if it, ok := m.list.SelectedItem().(fileItem); ok {
+				if it, ok := m.files.selectedItem(); ok {
 					if it.isDir {
 						m.dirStack = append(m.dirStack, m.cwd)
 						m.cwd = it.path
-                        items := scanDir(m.cwd)
-                        sort.Slice(items, func(i,j int) bool { if items[i].isDir != items[j].isDir { return items[i].isDir } ; return strings.ToLower(filepath.Base(items[i].path)) < strings.ToLower(filepath.Base(items[j].path)) })
-                        m.dirAll = items
-                        m.listPage = 0
-                        m.rebuildDirPage()
-						return m, m.loadPreview()
+						cmd := m.beginScan("", nil)
+						return m, cmd
 					}
 				}
 			}
 		case key.Matches(msg, m.keys.Back):
+            if m.files.tree != nil && m.treeBack() {
+                cmd := m.schedulePreview()
+                return m, cmd
+            }
 			if m.browsing && len(m.dirStack) > 0 {
+				// land on the directory we just left
+				from := m.cwd
 				m.cwd = m.dirStack[len(m.dirStack)-1]
 				m.dirStack = m.dirStack[:len(m.dirStack)-1]
-                items := scanDir(m.cwd)
-                sort.Slice(items, func(i,j int) bool { if items[i].isDir != items[j].isDir { return items[i].isDir } ; return strings.ToLower(filepath.Base(items[i].path)) < strings.ToLower(filepath.Base(items[j].path)) })
-                m.dirAll = items
-                m.listPage = 0
-                m.rebuildDirPage()
-				return m, m.loadPreview()
+				cmd := m.beginScan(from, nil)
+				return m, cmd
 			}
 		case key.Matches(msg, m.keys.Open):
-            m.jobs.running += len(m.targetItems())
             return m, m.runActionOnTargets(actOpen)
 		case key.Matches(msg, m.keys.Reveal):
-            m.jobs.running += len(m.targetItems())
             return m, m.runActionOnTargets(actReveal)
 		case key.Matches(msg, m.keys.ClearQ):
             m.mode = modeConfirm
             m.status = fmt.Sprintf("confirm clear quarantine for %d item(s)? y/N", len(m.targetItems()))
             return m, nil
 		case key.Matches(msg, m.keys.Chmod):
-			m.mode = modeChmod; m.filter.Placeholder = "octal (e.g. 644)"; m.filter.SetValue(""); m.filter.Focus();
+			m.openPerms()
+			return m, nil
 		case key.Matches(msg, m.keys.Filter):
-			m.filter.Placeholder = "filter"; m.filter.SetValue(""); m.filter.Focus()
+			m.mode = modeFilter; m.filter.Placeholder = "filter"; m.filter.SetValue(m.files.query); m.filter.Focus()
+			return m, nil
         case key.Matches(msg, m.keys.Refresh):
-            // Refresh both preview and file list
-            return m, tea.Batch(m.reloadList(), m.loadPreview())
+            // Refresh both preview and file list; cached previews are dropped
+            m.cache.purge()
+            paths := make([]string, 0, m.files.total())
+            for i := 0; i < m.files.total(); i++ { paths = append(paths, m.files.store.path(i)) }
+            cache := m.cache
+            drop := func() tea.Msg { cache.invalidate(paths); return nil }
+            reload := m.reloadList()
+            cmd := m.loadPreview()
+            return m, tea.Batch(drop, reload, cmd)
         case key.Matches(msg, m.keys.Select):
-            idx := m.list.Index()
-            if it, ok := m.list.SelectedItem().(fileItem); ok {
-                it.selected = !it.selected
-                m.list.SetItem(idx, it)
-            }
+            m.files.toggle(m.files.index())
             return m, nil
         case key.Matches(msg, m.keys.SelectAll):
-            for i := 0; i < len(m.list.Items()); i++ { if it, ok := m.list.Items()[i].(fileItem); ok { it.selected = true; m.list.SetItem(i, it) } }
+            m.files.selectAll()
             return m, nil
         case key.Matches(msg, m.keys.ClearSel):
-            for i := 0; i < len(m.list.Items()); i++ { if it, ok := m.list.Items()[i].(fileItem); ok { it.selected = false; m.list.SetItem(i, it) } }
+            m.files.clearSelection()
             return m, nil
         case key.Matches(msg, m.keys.Actions):
             m.refreshActions()
             m.mode = modeActions
             // Center overlay size is set in WindowSize
             return m, nil
-        case key.Matches(msg, m.keys.DirPrevPage):
-            if m.browsing && len(m.dirAll) > m.dirCap { m.listPage--; m.rebuildDirPage(); return m, nil }
-        case key.Matches(msg, m.keys.DirNextPage):
-            if m.browsing && len(m.dirAll) > m.dirCap { m.listPage++; m.rebuildDirPage(); return m, nil }
         case key.Matches(msg, m.keys.JobLog):
-            m.showJobLog = !m.showJobLog
+            m.mode = modeJobs
             return m, nil
-        case key.Matches(msg, m.keys.Undo):
-            return m, m.runUndo()
+        case key.Matches(msg, m.keys.CancelJobs):
+            m.status = fmt.Sprintf("cancelling %d job(s)", m.jobs.cancelAll())
+            return m, nil
+        case key.Matches(msg, m.keys.Debug):
+            m.showDebug = !m.showDebug
+            return m, nil
+        case key.Matches(msg, m.keys.Sort):
+            if !m.singleFile { m.mode = modeSort }
+            return m, nil
+        case key.Matches(msg, m.keys.DetailView):
+            if m.singleFile { return m, nil }
+            cmd := m.toggleDetail()
+            return m, cmd
+        case key.Matches(msg, m.keys.Columns):
+            if !m.singleFile { m.mode = modeColumns }
+            return m, nil
+        case key.Matches(msg, m.keys.NewTab):
+            if m.singleFile { return m, nil }
+            cmd := m.newTab()
+            m.fixPeer()
+            return m, cmd
+        case key.Matches(msg, m.keys.CloseTab):
+            cmd := m.closeTab()
+            m.fixPeer()
+            return m, cmd
+        case key.Matches(msg, m.keys.NextTab) && m.peerTab() >= 0:
+            cmd := m.focusPeer()
+            return m, cmd
+        case key.Matches(msg, m.keys.CopyTo), key.Matches(msg, m.keys.MoveTo):
+            if m.singleFile || len(m.targetItems()) == 0 { return m, nil }
+            if key.Matches(msg, m.keys.CopyTo) { m.transferInput(actCopyTo) } else { m.transferInput(actMoveToDir) }
+            return m, nil
+        case key.Matches(msg, m.keys.NextTab), key.Matches(msg, m.keys.PrevTab):
+            if len(m.tabs) < 2 { return m, nil }
+            step := 1
+            if key.Matches(msg, m.keys.PrevTab) { step = len(m.tabs) - 1 }
+            cmd := m.switchTab((m.tab + step) % len(m.tabs))
+            return m, cmd
+        case key.Matches(msg, m.keys.SendTab):
+            if len(m.tabs) < 2 { m.status = "no other tabs (ctrl+t opens one)"; return m, nil }
+            if len(m.targetItems()) > 0 { m.mode = modeSendTab }
+            return m, nil
+        case key.Matches(msg, m.keys.Layout):
+            if m.singleFile { return m, nil }
+            cmd := m.cycleLayout()
+            return m, cmd
+        case key.Matches(msg, m.keys.Tree):
+            cmd := m.toggleTree()
+            return m, cmd
+        case m.files.tree != nil && key.Matches(msg, m.keys.TreeOpen):
+            pos := m.files.index()
+            if pos >= m.files.len() { return m, nil }
+            cmd := m.treeExpand(m.files.view[pos])
+            m.files.refreshTree()
+            return m, cmd
+        case m.files.tree != nil && key.Matches(msg, m.keys.ExpandAll):
+            cmd := m.treeExpandAll()
+            return m, cmd
+        case m.files.tree != nil && key.Matches(msg, m.keys.CollapseAll):
+            m.treeCollapseAll()
+            cmd := m.schedulePreview()
+            return m, cmd
+        case key.Matches(msg, m.keys.TrashView):
+            cmd := m.openTrash()
+            return m, cmd
+        case key.Matches(msg, m.keys.Undo), key.Matches(msg, m.keys.Redo):
+            cmd := m.runUndo(key.Matches(msg, m.keys.Redo))
+            return m, cmd
 		}
 	}
 	// If entering input modes
 	switch m.mode {
-	case modeChmod:
+	case modeFilter:
 		var cmd tea.Cmd
-		m.filter, cmd = m.filter.Update(msg)
+		before := m.files.index()
 		if k, ok := msg.(tea.KeyMsg); ok {
-			s := k.String()
-			if s == "enter" {
-                oct := strings.TrimSpace(m.filter.Value())
-                targets := m.targetItems()
-                if len(targets) > 0 {
-                    // Run chmod on all targets asynchronously
-                    cmds := make([]tea.Cmd, 0, len(targets))
-                    for _, t := range targets { p := t.path; cmds = append(cmds, func() tea.Msg { _ = chmodPath(p, oct); return jobDoneMsg{path: p, act: actChmod, err: nil} }) }
-                    m.jobs.running += len(targets)
-                    m.status = "chmod applied"
-                    m.mode = modeList; m.filter.Blur(); return m, tea.Batch(tea.Batch(cmds...), m.loadPreview())
-                }
-                m.mode = modeList; m.filter.Blur(); return m, nil
-			} else if s == "esc" {
+			switch {
+			case k.Type == tea.KeyEnter:
 				m.mode = modeList; m.filter.Blur()
+				return m, nil
+			case k.Type == tea.KeyEsc:
+				m.mode = modeList; m.filter.Blur()
+				m.files.setFilter("")
+				pcmd := m.schedulePreview()
+				return m, pcmd
+			case k.Type == tea.KeyUp: m.files.move(-1)
+			case k.Type == tea.KeyDown: m.files.move(1)
+			default:
+				m.filter, cmd = m.filter.Update(msg)
+				if q := m.filter.Value(); q != m.files.query { m.files.setFilter(q); before = -1 }
 			}
+		} else {
+			m.filter, cmd = m.filter.Update(msg)
+		}
+		if m.files.index() != before {
+			pcmd := m.schedulePreview()
+			return m, tea.Batch(cmd, pcmd)
 		}
 		return m, cmd
 	case modeMoveToDir:
@@ -961,40 +1061,34 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if s == "enter" {
 				dst := strings.TrimSpace(m.filter.Value())
 				if dst != "" {
-					targets := m.targetItems()
-					ops := make([]op, 0, len(targets))
-					for i, t := range targets {
-						base := filepath.Base(t.path)
-						to := filepath.Join(dst, base)
-						// ensure unique with (n)
-						try := to
-						n := 1
-						for {
-							if _, err := os.Stat(try); os.IsNotExist(err) { break }
-							try = filepath.Join(dst, fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(base, filepath.Ext(base)), n, filepath.Ext(base)))
-							n++
-						}
-						ops = append(ops, op{from: t.path, to: try})
-						_ = i
-					}
-					m.pendingOps = ops
-					m.pendingAct = actMoveToDir
-					// Build dry-run overlay text
-					b := &strings.Builder{}
-					fmt.Fprintf(b, "Move preview → %s\n\n", dst)
-					for _, op := range ops { fmt.Fprintf(b, "%s\n  ↳ %s\n\n", op.from, op.to) }
-					m.opsOverlayText = b.String()
-					m.opsOverlay.SetContent(m.opsOverlayText)
-					m.mode = modeOpsPreview
-					m.status = "enter to confirm, esc to cancel"
+					m.filter.Blur()
+					m.planTransfer(m.pendingAct, dst) // move, copy or symlink, see transferInput
 					return m, nil
 				}
-				m.mode = modeList; m.filter.Blur(); return m, nil
+				m.mode = modeList; m.filter.Blur(); m.pendingAct = 0; return m, nil
 			} else if s == "esc" {
-				m.mode = modeList; m.filter.Blur()
+				m.mode = modeList; m.filter.Blur(); m.pendingAct = 0
 			}
 		}
 		return m, cmd
+	case modeChown:
+		var cmd tea.Cmd
+		m.filter, cmd = m.filter.Update(msg)
+		if k, ok := msg.(tea.KeyMsg); ok {
+			switch k.String() {
+			case "enter":
+				p, err := parseChown(m.filter.Value())
+				if err != nil { m.status = "chown: " + err.Error(); return m, cmd }
+				m.closeChownInput()
+				m.planChown(p)
+				return m, nil
+			case "esc":
+				m.closeChownInput(); m.mode = modeList; m.chown = nil
+				return m, nil
+			}
+			m.filter.SetSuggestions(chownSuggestions(m.filter.Value(), m.ownerNames[0], m.ownerNames[1]))
+		}
+		return m, cmd
 	case modeRenamePattern:
 		var cmd tea.Cmd
 		m.filter, cmd = m.filter.Update(msg)
@@ -1003,29 +1097,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if s == "enter" {
 				pat := strings.TrimSpace(m.filter.Value())
 				if pat != "" {
-					targets := m.targetItems()
-					ops := make([]op, 0, len(targets))
-					for idx, t := range targets {
-						dir := filepath.Dir(t.path)
-						bn := filepath.Base(t.path)
-						name := strings.TrimSuffix(bn, filepath.Ext(bn))
-						ext := filepath.Ext(bn)
-						out := pat
-						out = strings.ReplaceAll(out, "{name}", name)
-						out = strings.ReplaceAll(out, "{ext}", ext)
-						out = strings.ReplaceAll(out, "{n}", fmt.Sprintf("%d", idx+1))
-						to := filepath.Join(dir, out)
-						ops = append(ops, op{from: t.path, to: to})
-					}
-					m.pendingOps = ops
-					m.pendingAct = actRenamePattern
-					b := &strings.Builder{}
-					fmt.Fprintf(b, "Rename preview\n\n")
-					for _, op := range ops { fmt.Fprintf(b, "%s\n  ↳ %s\n\n", op.from, op.to) }
-					m.opsOverlayText = b.String()
-					m.opsOverlay.SetContent(m.opsOverlayText)
-					m.mode = modeOpsPreview
-					m.status = "enter to confirm, esc to cancel"
+					if err := m.planRename(pat); err != nil { m.status = "pattern: " + err.Error(); return m, cmd }
+					m.filter.Blur()
 					return m, nil
 				}
 				m.mode = modeList; m.filter.Blur(); return m, nil
@@ -1043,11 +1116,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				app := strings.TrimSpace(m.filter.Value())
 				targets := m.targetItems()
 				if app != "" && len(targets) > 0 {
-					cmds := make([]tea.Cmd, 0, len(targets))
-					for _, t := range targets { p := t.path; a := app; cmds = append(cmds, func() tea.Msg { openWithPath(a, p); return jobDoneMsg{path: p, act: actOpenWith, err: nil} }) }
-					m.jobs.running += len(targets)
-					m.status = "opened with"
-					m.mode = modeList; m.filter.Blur(); return m, tea.Batch(tea.Batch(cmds...), m.loadPreview())
+					ops := make([]op, len(targets))
+					for i, t := range targets { ops[i] = op{from: t.path} }
+					cmd := m.startBatch(actOpenWith, ops, func(_ context.Context, o op) (journalOp, string, error) { openWithPath(app, o.from); return journalOp{}, "", nil })
+					m.mode = modeList; m.filter.Blur()
+					pcmd := m.loadPreview()
+					return m, tea.Batch(cmd, pcmd)
 				}
 				m.mode = modeList; m.filter.Blur(); return m, nil
 			} else if s == "esc" {
@@ -1056,26 +1130,35 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, cmd
 	}
-	var cmd tea.Cmd
-	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	return m, nil
 }
 
 func (m model) View() string {
     title := m.theme.title.Render(" finfo TUI (alpha)")
+    if !m.singleFile { title += m.tabBar() }
     if m.singleFile {
         // Minimalist single-file view: just header + preview + help line
         status := m.theme.status.Render(strings.TrimSpace(m.status))
         base := title + "\n" + m.preview.View() + "\n" + m.help.View(m.keys) + "  " + status + "\n"
         return base
     }
-    left := m.list.View()
-    right := ""
-    if m.showPreview { right = m.preview.View() }
+    var panes string
+    if m.layout.kind == layoutMiller {
+        panes = m.millerView()
+    } else if m.layout.kind == layoutDual {
+        panes = m.dualView()
+    } else {
+        right := ""
+        if m.showPreview { right = m.preview.View() }
+        panes = lipgloss.JoinHorizontal(lipgloss.Top, m.files.View(), right)
+    }
     // Build dynamic status
-    selCount := 0
-    for i := 0; i < len(m.list.Items()); i++ { if it, ok := m.list.Items()[i].(fileItem); ok && it.selected { selCount++ } }
-    jobs := fmt.Sprintf("jobs %s %d ▸ ✓%d ✗%d", m.spin.View(), m.jobs.running, m.jobs.done, m.jobs.failed)
+    selCount := m.files.nsel
+    running, done, failed := m.jobs.counts()
+    jobs := fmt.Sprintf("jobs %s %d ▸ ✓%d ✗%d", m.spin.View(), running, done, failed)
+    for _, j := range m.jobs.list {
+        if j.current() == jobRunning { if p := j.progress(); p != "" { jobs += "  " + p } }
+    }
     status := m.theme.status.Render(strings.TrimSpace(fmt.Sprintf("%s  |  selected %d  |  %s", m.status, selCount, jobs)))
 	// Input line (filter/chmod) when focused
 	inputLine := ""
@@ -1084,14 +1167,12 @@ func (m model) View() string {
 	}
     // Footer shows page hint for large dirs
     footer := m.help.View(m.keys) + "  " + status
-    if m.browsing {
-        total := len(m.dirAll)
-        if m.dirCap > 0 && total > m.dirCap {
-            pages := (total + m.dirCap - 1) / m.dirCap
-            footer += fmt.Sprintf("  |  Page %d/%d (</> to switch)", m.listPage+1, pages)
-        }
+    if m.scanning {
+        footer += fmt.Sprintf("  |  scanning %s %d", m.spin.View(), m.scanSeen)
+    } else if m.scanErrs > 0 {
+        footer += fmt.Sprintf("  |  %d read errors", m.scanErrs)
     }
-    base := title + "\n" + lipgloss.JoinHorizontal(lipgloss.Top, left, right) + inputLine + "\n" + footer + "\n"
+    base := title + "\n" + panes + inputLine + "\n" + footer + "\n"
     if m.mode == modeActions {
         w := lipgloss.Width(base)
         overlay := m.theme.overlay.Render("Actions\n" + m.actions.View() + "\nenter to run, esc to close")
@@ -1103,13 +1184,45 @@ func (m model) View() string {
         overlay := m.theme.overlay.Render(m.status)
         return base + "\n" + overlay
     }
+    if m.mode == modeSort {
+        return base + "\n" + m.theme.overlay.Render(sortMenu(m.sort))
+    }
+    if m.mode == modeColumns {
+        return base + "\n" + m.theme.overlay.Render(columnsMenu(m.files.cols))
+    }
+    if m.mode == modeSendTab {
+        return base + "\n" + m.theme.overlay.Render(m.sendMenu())
+    }
+    if m.mode == modeTrash {
+        return base + "\n" + m.theme.overlay.Render(m.trashMenu())
+    }
+    if m.mode == modeJobs {
+        return base + "\n" + m.theme.overlay.Render(m.jobsView())
+    }
+    if m.mode == modeChmod {
+        return base + "\n" + m.theme.overlay.Render(m.permView())
+    }
     if m.mode == modeHelp {
         b := &strings.Builder{}
         fmt.Fprintf(b, "Keymap\n\n")
-        fmt.Fprintf(b, "Navigation: ↑/k, ↓/j, / filter, enter select\n")
+        fmt.Fprintf(b, "Navigation: ↑/k, ↓/j, [/] or pgup/pgdn page, g/$ top/bottom, / filter (esc clears), enter select\n")
         fmt.Fprintf(b, "Actions: a palette, c copy, o open, E reveal, r clear quarantine, m chmod\n")
         fmt.Fprintf(b, "Selection: space toggle, A all, V clear\n")
-        fmt.Fprintf(b, "Misc: l toggle long, R refresh, q quit, ? help\n\n")
+        fmt.Fprintf(b, "Sort: s then n name, s size, m modified, c created, a accessed, e ext, t type, o owner, p perms; r reverse, v natural\n")
+        fmt.Fprintf(b, "Tree view: t toggle, enter/→ expand, ←/h collapse or parent, + expand all, - collapse all\n")
+        fmt.Fprintf(b, "Detail view: L toggle columns (perms, links, owner, size, mtime, git), C pick columns, T in picker: relative/absolute time\n")
+        fmt.Fprintf(b, "Preview: 1 header, 2 essentials, 3 timeline, 4 paths, 5 security, 6 actions\n")
+        fmt.Fprintf(b, "Tabs: ctrl+t new tab here, ctrl+w close, tab/shift+tab switch, > copy/move selection to another tab\n")
+        fmt.Fprintf(b, "Dual pane (M): tab switches pane, F5 copy / F6 move to the other pane (destination prefilled)\n")
+        fmt.Fprintf(b, "Trash: X browse (r restore, d delete permanently, E empty); trashing never deletes outright\n")
+        fmt.Fprintf(b, "Undo: U undo last batch, ctrl+r redo; the journal is kept across restarts\n")
+        fmt.Fprintf(b, "Jobs: J job log (x cancel, X cancel all, r retry failed), ctrl+x cancel all jobs\n")
+        fmt.Fprintf(b, "Copy preview: p conflict policy (rename/skip/overwrite/newer wins), v verify checksums\n")
+        fmt.Fprintf(b, "Rename pattern: {name} {ext} {n:width:start:step} {mtime:YYYY-MM-DD} {date} (EXIF) |upper|lower|title; /regex/template uses {1}…\n")
+        fmt.Fprintf(b, "Permissions (m): ←↑↓→ and space edit the grid, e octal or symbolic (g+w,o-rwx), tab files/dirs, R recursive, u unchanged\n")
+        fmt.Fprintf(b, "Change owner (a): user, user:group or :group with tab completion; R in the preview applies recursively\n")
+        fmt.Fprintf(b, "Rename in editor (a): edit the numbered names in $VISUAL/$EDITOR; swaps and cycles go through temporary names\n")
+        fmt.Fprintf(b, "Misc: l toggle long, M layout (split/miller/dual), R refresh, q quit, ? help\n\n")
         fmt.Fprintf(b, "Batch ops apply to selected items; otherwise current item.")
         overlay := m.theme.overlay.Render(b.String())
         return base + "\n" + overlay
@@ -1118,13 +1231,16 @@ func (m model) View() string {
         overlay := m.theme.overlay.Render(m.opsOverlayText)
         return base + "\n" + overlay
     }
-    if m.showJobLog {
-        // show last ~20 job logs
-        start := 0
-        if len(m.jobLog) > 20 { start = len(m.jobLog) - 20 }
+    if m.showDebug {
+        st := m.cache.stats()
         b := &strings.Builder{}
-        fmt.Fprintf(b, "Job log (latest)\n\n")
-        for i := start; i < len(m.jobLog); i++ { fmt.Fprintf(b, "%s\n", m.jobLog[i]) }
+        fmt.Fprintf(b, "Debug\n\n")
+        ratio := 0.0
+        if total := st.hits + st.diskHits + st.misses; total > 0 { ratio = float64(st.hits+st.diskHits) / float64(total) * 100 }
+        fmt.Fprintf(b, "Preview cache: %d/%d entries  disk: %v\n", st.size, st.cap, st.disk)
+        fmt.Fprintf(b, "Hits: %d  disk hits: %d  misses: %d  (%.0f%% hit)\n", st.hits, st.diskHits, st.misses, ratio)
+        if m.prefetch != nil { fmt.Fprintf(b, "Prefetch: depth %d, %d prefetched\n", m.prefetch.depth, st.prefetched) } else { fmt.Fprintf(b, "Prefetch: off\n") }
+        fmt.Fprintf(b, "Engine: %s  last preview from: %s  preview seq: %d", m.engine, m.previewEngine, m.previewSeq)
         overlay := m.theme.overlay.Render(b.String())
         return base + "\n" + overlay
     }
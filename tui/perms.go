@@ -0,0 +1,333 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Permission specs ----------
+
+// permSpec is a mode change: an absolute octal mode, or chmod(1) symbolic
+// clauses ("g+w,o-rwx", "a=rX", "u+s") applied to each entry's own mode.
+// Modes are unix bits (07777). A missing who means a, without a umask.
+type permSpec struct {
+    text    string
+    abs     bool
+    mode    uint32
+    clauses []permClause
+}
+
+type permClause struct {
+    who  uint32 // bits the clause may touch
+    op   byte   // + - =
+    perm string // from rwxXst, or one of u g o to copy that class
+}
+
+func parsePermSpec(s string) (*permSpec, error) {
+    s = strings.TrimSpace(s)
+    if s == "" { return nil, errors.New("empty mode") }
+    if v, err := strconv.ParseUint(s, 8, 32); err == nil {
+        if len(s) > 4 || v > 07777 { return nil, fmt.Errorf("%s: octal modes go up to 7777", s) }
+        return &permSpec{text: s, abs: true, mode: uint32(v)}, nil
+    }
+    p := &permSpec{text: s}
+    for _, c := range strings.Split(s, ",") {
+        i := 0
+        var who uint32
+        for ; i < len(c) && strings.IndexByte("ugoa", c[i]) >= 0; i++ {
+            switch c[i] {
+            case 'u': who |= 04700
+            case 'g': who |= 02070
+            case 'o': who |= 01007
+            case 'a': who |= 07777
+            }
+        }
+        if who == 0 { who = 07777 }
+        if i == len(c) { return nil, fmt.Errorf("%q: expected + - or = after who", c) }
+        for i < len(c) {
+            if strings.IndexByte("+-=", c[i]) < 0 { return nil, fmt.Errorf("%q: unexpected %q", c, c[i]) }
+            cl := permClause{who: who, op: c[i]}
+            i++
+            j := i
+            for j < len(c) && strings.IndexByte("+-=", c[j]) < 0 { j++ }
+            cl.perm = c[i:j]
+            switch {
+            case cl.perm == "u", cl.perm == "g", cl.perm == "o":
+            case strings.Trim(cl.perm, "rwxXst") != "": return nil, fmt.Errorf("%q: permissions are r w x X s t, or u g o", c)
+            }
+            p.clauses = append(p.clauses, cl)
+            i = j
+        }
+    }
+    return p, nil
+}
+
+// apply returns the mode an entry with mode cur ends up with.
+func (p *permSpec) apply(cur uint32, isDir bool) uint32 {
+    if p.abs { return p.mode }
+    for _, cl := range p.clauses {
+        var bits uint32
+        switch cl.perm {
+        case "u": v := cur >> 6 & 7; bits = v<<6 | v<<3 | v
+        case "g": v := cur >> 3 & 7; bits = v<<6 | v<<3 | v
+        case "o": v := cur & 7; bits = v<<6 | v<<3 | v
+        default:
+            for _, c := range cl.perm {
+                switch c {
+                case 'r': bits |= 0444
+                case 'w': bits |= 0222
+                case 'x': bits |= 0111
+                case 'X': if isDir || cur&0111 != 0 { bits |= 0111 }
+                case 's': bits |= 06000
+                case 't': bits |= 01000
+                }
+            }
+        }
+        bits &= cl.who
+        switch cl.op {
+        case '+': cur |= bits
+        case '-': cur &^= bits
+        case '=': cur = cur&^cl.who | bits
+        }
+    }
+    return cur
+}
+
+// fileMode turns unix mode bits into the fs.FileMode os.Chmod takes.
+func fileMode(m uint32) fs.FileMode {
+    fm := fs.FileMode(m & 0777)
+    if m&04000 != 0 { fm |= fs.ModeSetuid }
+    if m&02000 != 0 { fm |= fs.ModeSetgid }
+    if m&01000 != 0 { fm |= fs.ModeSticky }
+    return fm
+}
+
+// ---------- Permission editor ----------
+
+// The editor keeps a mode change for files and one for directories (tab
+// switches between them); the grid edits an absolute mode, e takes an
+// octal or symbolic expression. Recursive applies to directory contents as
+// well, files and directories each getting their own change.
+
+type permTarget struct {
+    path  string
+    mode  uint32
+    isDir bool
+}
+
+type permEditor struct {
+    targets   []permTarget
+    side      int          // 0 files, 1 directories
+    spec      [2]*permSpec // nil leaves that kind alone
+    base      [2]uint32    // mode the grid starts from
+    row, col  int          // grid cursor; row 3 is setuid/setgid/sticky
+    recursive bool
+    typing    bool
+    err       string
+}
+
+var permSides = [2]string{"files", "directories"}
+
+// openPerms starts the editor on the targets.
+func (m *model) openPerms() {
+    pe := &permEditor{base: [2]uint32{0644, 0755}}
+    seen := [2]bool{}
+    for _, t := range m.targetItems() {
+        fi, err := os.Stat(t.path)
+        if err != nil { continue }
+        pt := permTarget{path: t.path, mode: inspect.StatOf(fi).Mode, isDir: fi.IsDir()}
+        pe.targets = append(pe.targets, pt)
+        side := 0
+        if pt.isDir { side = 1 }
+        if !seen[side] { pe.base[side], seen[side] = pt.mode, true }
+    }
+    if len(pe.targets) == 0 { m.status = "nothing to chmod"; return }
+    if !seen[0] { pe.side = 1 }
+    m.perm = pe
+    m.mode = modeChmod
+}
+
+// shown is the mode the grid shows for a side.
+func (pe *permEditor) shown(side int) uint32 {
+    if pe.spec[side] == nil { return pe.base[side] }
+    return pe.spec[side].apply(pe.base[side], side == 1)
+}
+
+func (pe *permEditor) specFor(isDir bool) *permSpec {
+    if isDir { return pe.spec[1] }
+    return pe.spec[0]
+}
+
+// gridBit is the mode bit under the cursor.
+func (pe *permEditor) gridBit() uint32 {
+    if pe.row == 3 { return 04000 >> uint(pe.col) }
+    return (4 >> uint(pe.col)) << uint(3*(2-pe.row))
+}
+
+func (m *model) permKey(msg tea.KeyMsg) tea.Cmd {
+    pe := m.perm
+    if pe.typing {
+        switch msg.String() {
+        case "esc":
+            pe.typing, pe.err = false, ""
+            m.filter.Blur()
+        case "enter":
+            spec, err := parsePermSpec(m.filter.Value())
+            if err != nil { pe.err = err.Error(); return nil }
+            pe.spec[pe.side], pe.typing, pe.err = spec, false, ""
+            m.filter.Blur()
+        default:
+            var cmd tea.Cmd
+            m.filter, cmd = m.filter.Update(msg)
+            return cmd
+        }
+        return nil
+    }
+    switch msg.String() {
+    case "esc", "q": m.perm = nil; m.mode = modeList
+    case "up", "k": if pe.row > 0 { pe.row-- }
+    case "down", "j": if pe.row < 3 { pe.row++ }
+    case "left", "h": if pe.col > 0 { pe.col-- }
+    case "right", "l": if pe.col < 2 { pe.col++ }
+    case " ", "x":
+        mode := pe.shown(pe.side) ^ pe.gridBit()
+        pe.spec[pe.side] = &permSpec{text: fmt.Sprintf("%04o", mode), abs: true, mode: mode}
+    case "tab": pe.side = 1 - pe.side
+    case "R": pe.recursive = !pe.recursive
+    case "u": pe.spec[pe.side] = nil // back to unchanged
+    case "e", ":":
+        pe.typing, pe.err = true, ""
+        m.filter.Placeholder = "octal (750) or symbolic (u+x, g-w,o=, a=rX)"
+        m.filter.SetValue("")
+        if s := pe.spec[pe.side]; s != nil { m.filter.SetValue(s.text) }
+        m.filter.Focus()
+    case "enter":
+        if pe.spec[0] == nil && pe.spec[1] == nil { pe.err = "nothing changed yet"; return nil }
+        cmd := m.startJob("chmod", targetLabel(pe.paths()), permJob(m.journal.newBatch(), pe.targets, pe.spec, pe.recursive))
+        m.perm = nil
+        m.mode = modeList
+        return cmd
+    }
+    return nil
+}
+
+func (pe *permEditor) paths() []string {
+    out := make([]string, len(pe.targets))
+    for i, t := range pe.targets { out[i] = t.path }
+    return out
+}
+
+// permView renders the grid, both changes and the per-target preview.
+func (m *model) permView() string {
+    pe := m.perm
+    b := &strings.Builder{}
+    fmt.Fprintf(b, "Permissions · %d target(s)   editing %s (tab)\n\n", len(pe.targets), permSides[pe.side])
+    mode := pe.shown(pe.side)
+    cell := func(row, col int, label string, bit uint32) string {
+        v := "-"
+        if mode&bit != 0 { v = label }
+        if row == pe.row && col == pe.col && !pe.typing { return "[" + v + "]" }
+        return " " + v + " "
+    }
+    for r, who := range []string{"user   ", "group  ", "other  "} {
+        fmt.Fprintf(b, "  %s", who)
+        for c, l := range []string{"r", "w", "x"} { b.WriteString(cell(r, c, l, (4>>uint(c))<<uint(3*(2-r)))) }
+        b.WriteString("\n")
+    }
+    b.WriteString("  special")
+    for c, l := range []string{"s", "s", "t"} { b.WriteString(cell(3, c, l, 04000>>uint(c))) }
+    b.WriteString("  setuid, setgid, sticky\n\n")
+    for side, name := range permSides {
+        change := "unchanged"
+        if s := pe.spec[side]; s != nil {
+            change = s.text
+            if s.abs { change = fmt.Sprintf("%s %04o", permString('-', s.mode)[1:], s.mode) }
+        }
+        fmt.Fprintf(b, "  %-12s %s\n", name+":", change)
+    }
+    rec := "off"
+    if pe.recursive { rec = "on, contents get the change for their kind" }
+    fmt.Fprintf(b, "  %-12s %s\n\n", "recursive:", rec)
+    rows := m.height - 24
+    if rows < 3 { rows = 3 }
+    for k, t := range pe.targets {
+        if k == rows { fmt.Fprintf(b, "  … %d more\n", len(pe.targets)-k); break }
+        typ, name := byte('-'), filepath.Base(t.path)
+        if t.isDir { typ, name = 'd', name+"/" }
+        to := t.mode
+        if s := pe.specFor(t.isDir); s != nil { to = s.apply(t.mode, t.isDir) }
+        arrow := "→"
+        if to == t.mode { arrow = "=" }
+        fmt.Fprintf(b, "  %s %s %s  %s\n", permString(typ, t.mode), arrow, permString(typ, to), name)
+    }
+    if pe.err != "" { fmt.Fprintf(b, "\n  %s\n", pe.err) }
+    if pe.typing {
+        b.WriteString("\n  enter sets the expression · esc back to the grid")
+    } else {
+        b.WriteString("\n  ←↑↓→ move · space toggle · e expression · u unchanged · tab files/dirs · R recursive · enter apply · esc cancel")
+    }
+    return b.String()
+}
+
+// ---------- Applying ----------
+
+// permJob applies the changes, carrying on past entries it cannot change;
+// every mode that changed is journalled with its previous value.
+func permJob(batch int64, targets []permTarget, spec [2]*permSpec, recursive bool) jobFunc {
+    return func(ctx context.Context, j *job) (tea.Msg, error) {
+        j.total.Store(int64(len(targets)))
+        msg := batchDoneMsg{act: actChmod, batch: batch}
+        failed, tried := 0, 0
+        var first error
+        chmod := func(p string, fi fs.FileInfo) {
+            s := spec[0]
+            if fi.IsDir() { s = spec[1] }
+            if s == nil { return }
+            cur := inspect.StatOf(fi).Mode
+            to := s.apply(cur, fi.IsDir())
+            if to == cur { return }
+            tried++
+            if err := os.Chmod(p, fileMode(to)); err != nil {
+                failed++
+                if errors.Is(err, fs.ErrPermission) { err = fmt.Errorf("%s: not permitted (only the owner or root can change its mode)", p) }
+                if first == nil { first = err }
+                j.notes = append(j.notes, err.Error())
+                return
+            }
+            msg.recs = append(msg.recs, journalOp{Kind: "chmod", From: p, Mode: uint32(fileMode(cur)), NewMode: uint32(fileMode(to))})
+            msg.n++
+        }
+        for _, t := range targets {
+            if err := ctx.Err(); err != nil { return msg, err }
+            fi, err := os.Stat(t.path)
+            if err != nil { failed++; tried++; j.notes = append(j.notes, err.Error()); if first == nil { first = err }; continue }
+            if recursive && fi.IsDir() {
+                // Like chmod -R, a directory changes before its entries are
+                // read, so adding r or x to it lets the walk go on.
+                filepath.WalkDir(t.path, func(p string, d fs.DirEntry, err error) error {
+                    if ctx.Err() != nil { return ctx.Err() }
+                    if err != nil { failed++; j.notes = append(j.notes, err.Error()); return nil }
+                    if d.Type()&fs.ModeSymlink != 0 { return nil }
+                    info, err := d.Info()
+                    if err == nil { chmod(p, info) }
+                    return nil
+                })
+            } else {
+                chmod(t.path, fi)
+            }
+            j.n.Add(1)
+        }
+        if err := ctx.Err(); err != nil { return msg, err }
+        if failed > 0 && tried > 1 { return msg, fmt.Errorf("%d of %d failed: %w", failed, tried, first) }
+        return msg, first
+    }
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+    "context"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestPermSpec(t *testing.T) {
+    tests := []struct {
+        spec  string
+        cur   uint32
+        isDir bool
+        want  uint32
+    }{
+        {"755", 0o600, false, 0o755},
+        {"0644", 0o777, true, 0o644},
+        {"4755", 0o644, false, 0o4755},
+        {"u+x", 0o644, false, 0o744},
+        {"go-w", 0o666, false, 0o644},
+        {"a=r", 0o4755, false, 0o444},
+        {"=r", 0o755, false, 0o444},
+        {"+x", 0o644, false, 0o755},
+        {"+t", 0o755, true, 0o1755},
+        {"o+t", 0o755, true, 0o1755},
+        {"u+s", 0o755, false, 0o4755},
+        {"g+s", 0o755, true, 0o2755},
+        {"a+s", 0o755, false, 0o6755},
+        {"u-s", 0o4755, false, 0o755},
+        {"u+rwx,g=rx,o=", 0o000, false, 0o750},
+        {"u=rw,go=r", 0o777, false, 0o644},
+        {"g=u", 0o740, false, 0o770},
+        {"o=g", 0o751, false, 0o755},
+        {"u+x-w", 0o644, false, 0o544},
+        {"ug+w,o-rwx", 0o444, false, 0o660},
+        // X: execute only for directories or entries someone can already execute
+        {"a+X", 0o644, false, 0o644},
+        {"a+X", 0o744, false, 0o755},
+        {"a+X", 0o600, true, 0o711},
+        {"go=rX", 0o600, false, 0o644},
+        {"go=rX", 0o700, false, 0o755},
+        {"go=rX", 0o700, true, 0o755},
+        {"u+", 0o644, false, 0o644},
+    }
+    for _, tt := range tests {
+        p, err := parsePermSpec(tt.spec)
+        if err != nil { t.Errorf("parsePermSpec(%q): %v", tt.spec, err); continue }
+        if got := p.apply(tt.cur, tt.isDir); got != tt.want {
+            t.Errorf("%q on %04o (dir %v) = %04o, want %04o", tt.spec, tt.cur, tt.isDir, got, tt.want)
+        }
+    }
+}
+
+func TestPermSpecErrors(t *testing.T) {
+    for _, spec := range []string{"", "  ", "8", "17777", "00755", "u", "ugo", "u+q", "u+x,", "z+x", "u+rw=k", "rwx", "u*x"} {
+        if _, err := parsePermSpec(spec); err == nil { t.Errorf("parsePermSpec(%q) succeeded", spec) }
+    }
+}
+
+func TestFileMode(t *testing.T) {
+    tests := []struct {
+        mode uint32
+        want fs.FileMode
+    }{
+        {0o755, 0o755},
+        {0o4755, fs.ModeSetuid | 0o755},
+        {0o2750, fs.ModeSetgid | 0o750},
+        {0o1777, fs.ModeSticky | 0o777},
+    }
+    for _, tt := range tests {
+        if got := fileMode(tt.mode); got != tt.want { t.Errorf("fileMode(%04o) = %v, want %v", tt.mode, got, tt.want) }
+    }
+}
+
+func TestPermJobRefreshesPreview(t *testing.T) {
+    dir := t.TempDir()
+    f := filepath.Join(dir, "f")
+    os.WriteFile(f, nil, 0o644)
+    m := undoTestModel(t, dir)
+    if msg := runPreview(context.Background(), m.cache, f, false, ""); msg.doc == nil || msg.doc.Perms.Octal != "644" {
+        t.Fatalf("preview before chmod: %+v", msg)
+    }
+
+    s, err := parsePermSpec("600")
+    if err != nil { t.Fatal(err) }
+    m = runJob(t, m, m.startJob("chmod", f, permJob(m.journal.newBatch(), []permTarget{{path: f, mode: 0o644}}, [2]*permSpec{s, s}, false)))
+    if _, found := m.cache.items[cacheID(f, false)]; found { t.Error("cached preview survived the chmod") }
+    msg := runPreview(context.Background(), m.cache, f, false, "")
+    if msg.doc == nil || msg.doc.Perms.Octal != "600" { t.Errorf("preview after chmod: %+v, want octal 600", msg.doc) }
+}
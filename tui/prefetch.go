@@ -0,0 +1,99 @@
+package main
+
+import (
+    "context"
+    "os"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// ---------- Background prefetching ----------
+
+type prefetchReq struct {
+    ctx  context.Context
+    path string
+    long bool
+}
+
+// prefetcher speculatively inspects items around the cursor with a bounded
+// worker pool, feeding the preview cache. Each submit supersedes the
+// previous batch: queued requests of older batches are skipped and their
+// in-flight inspections cancelled. Each inspection gets the same time limit
+// as a foreground preview, so a hung file cannot hold a worker.
+type prefetcher struct {
+    depth   int
+    engine  string
+    timeout time.Duration
+    cache   *previewCache
+    queue   chan prefetchReq
+    mu      sync.Mutex
+    cancel  context.CancelFunc
+}
+
+// prefetcherFromEnv reads FINFOTUI_PREFETCH (items above and below the
+// cursor, 0 disables) and FINFOTUI_PREFETCH_WORKERS.
+func prefetcherFromEnv(cache *previewCache, engine string, timeout time.Duration) *prefetcher {
+    depth := 3
+    if v := os.Getenv("FINFOTUI_PREFETCH"); v != "" {
+        if n, err := strconv.Atoi(v); err == nil && n >= 0 { depth = n }
+    }
+    workers := 2
+    if v := os.Getenv("FINFOTUI_PREFETCH_WORKERS"); v != "" {
+        if n, err := strconv.Atoi(v); err == nil && n > 0 { workers = n }
+    }
+    if depth == 0 { return nil }
+    p := &prefetcher{depth: depth, engine: engine, timeout: timeout, cache: cache, queue: make(chan prefetchReq, 256)}
+    for i := 0; i < workers; i++ { go p.work() }
+    return p
+}
+
+func (p *prefetcher) work() {
+    for r := range p.queue {
+        if r.ctx.Err() != nil || p.cache.contains(r.path, r.long) { continue }
+        ctx, cancel := context.WithTimeout(r.ctx, p.timeout)
+        if msg := runPreview(ctx, p.cache, r.path, r.long, p.engine); msg.doc != nil && msg.state == previewIdle { p.cache.notePrefetch() }
+        cancel()
+    }
+}
+
+// submit replaces the pending batch. It never blocks the UI goroutine:
+// requests that do not fit in the queue are dropped.
+func (p *prefetcher) submit(paths []string, long bool) {
+    if p == nil { return }
+    p.mu.Lock()
+    if p.cancel != nil { p.cancel() }
+    ctx, cancel := context.WithCancel(context.Background())
+    p.cancel = cancel
+    p.mu.Unlock()
+    for _, path := range paths {
+        select {
+        case p.queue <- prefetchReq{ctx: ctx, path: path, long: long}:
+        default: return
+        }
+    }
+}
+
+// prefetchAround queues the items within depth of the cursor, nearest first.
+func (m *model) prefetchAround() {
+    if m.prefetch == nil || !m.showPreview { return }
+    idx := m.files.index()
+    paths := make([]string, 0, 2*m.prefetch.depth)
+    for d := 1; d <= m.prefetch.depth; d++ {
+        for _, i := range []int{idx + d, idx - d} {
+            if i < 0 || i >= m.files.len() { continue }
+            paths = append(paths, m.files.itemAt(i).path)
+        }
+    }
+    m.prefetch.submit(paths, m.long)
+}
+
+// prefetchScreen queues the first screen of a freshly entered directory.
+func (m *model) prefetchScreen() {
+    if m.prefetch == nil || !m.showPreview { return }
+    n := m.files.perPage()
+    if n > m.files.len() { n = m.files.len() }
+    paths := make([]string, 0, n)
+    for i := 1; i < n; i++ { paths = append(paths, m.files.itemAt(i).path) }
+    m.prefetch.submit(paths, m.long)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/charmbracelet/lipgloss"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+)
+
+// ---------- Sectioned preview ----------
+
+// previewSection identifies a block of the rendered preview; Jump1–Jump6
+// scroll to the matching section.
+type previewSection int
+
+const (
+    secHeader previewSection = iota
+    secEssentials
+    secTimeline
+    secPaths
+    secSecurity
+    secActions
+    secTips
+    numSections
+)
+
+var sectionTitles = [numSections]string{"HEADER", "ESSENTIALS", "TIMELINE", "PATHS", "SECURITY & PROVENANCE", "ACTIONS", "TIPS"}
+
+// sectionOffsets holds the first line of each section, -1 when not rendered.
+type sectionOffsets [numSections]int
+
+// previewBuilder accumulates lines and remembers where each section starts.
+type previewBuilder struct {
+    th    theme
+    width int
+    lines []string
+    offs  sectionOffsets
+}
+
+func (b *previewBuilder) section(s previewSection) {
+    if len(b.lines) > 0 { b.lines = append(b.lines, "") }
+    if b.offs[s] < 0 { b.offs[s] = len(b.lines) }
+    b.lines = append(b.lines, b.th.section.Render(sectionTitles[s]))
+    w := b.width - 2
+    if w > 100 { w = 100 }
+    if w < 10 { w = 10 }
+    b.lines = append(b.lines, b.th.status.Render(strings.Repeat("─", w)))
+}
+
+// kv matches _kv: fixed 12-column label, value tinted by label kind.
+func (b *previewBuilder) kv(label, value string) {
+    switch label {
+    case "Size", "Lines", "Pages": value = b.th.num.Render(value)
+    case "Rel", "Abs", "Symlink": value = b.th.path.Render(value)
+    }
+    b.lines = append(b.lines, "  "+b.th.label.Render(fmt.Sprintf("%-12s", label+":"))+" "+value)
+}
+
+func (b *previewBuilder) line(s string) { b.lines = append(b.lines, s) }
+
+func (b *previewBuilder) dim(s string) string { return b.th.status.Render(s) }
+
+// renderPreview lays out a document with the same sections as the zsh
+// pretty output. Brief mode hides Timeline, Paths and Security like --brief.
+func renderPreview(d *inspect.Document, long bool, width int, th theme) (string, sectionOffsets) {
+    b := &previewBuilder{th: th, width: width, offs: noSections()}
+    now := time.Now()
+
+    // Header: one-line headline, then file + full type
+    b.offs[secHeader] = 0
+    short := d.Type.Description
+    if i := strings.IndexByte(short, ','); i >= 0 { short = short[:i] }
+    headSize := d.Size.Human
+    if d.IsDir { headSize = "—" }
+    head := lipgloss.NewStyle().Bold(true).Render(d.Name) + " · " + short + " · " + headSize
+    if d.Lines != nil { head += fmt.Sprintf(" · %d lines", *d.Lines) }
+    b.line(head)
+    b.section(secHeader)
+    htype := d.Type.Description
+    if d.Type.Charset != "" && d.Type.IsText == "text" { htype += " " + b.dim("("+d.Type.Charset+")") }
+    b.kv("File", d.Name+" "+b.dim("–")+" "+htype)
+
+    // Essentials
+    b.section(secEssentials)
+    bytesDisp := fmt.Sprintf("%d B", d.Size.Bytes)
+    if d.IsDir { b.kv("Size", "— "+b.dim("("+bytesDisp+")")) } else { b.kv("Size", sizeFmt(d.Size.Bytes, unitScheme())+" "+b.dim("("+bytesDisp+")")) }
+    if d.Lines != nil && !d.IsDir && d.Type.IsText == "text" { b.kv("Lines", fmt.Sprintf("%d", *d.Lines)) }
+    mime := d.Type.Mime
+    if d.Type.Charset != "" && !strings.Contains(mime, "charset") { mime += "; charset=" + d.Type.Charset }
+    if mime != "" { b.kv("Type", mime) }
+    if d.Owner.User != "" || d.Perms.Symbolic != "" {
+        b.kv("Owner", d.Owner.User+":"+d.Owner.Group+" "+b.dim("|")+" "+d.Perms.Symbolic+" "+b.dim("("+d.Perms.Octal+")"))
+    }
+    if d.Perms.Explain != "" { b.kv("Access", d.Perms.Explain) }
+    if d.IsDir {
+        b.kv("Entries", fmt.Sprintf("%d dirs, %d files", d.Dir.NumDirs, d.Dir.NumFiles))
+        if d.Dir.SizeHuman != "" { b.kv("Disk", d.Dir.SizeHuman) }
+    }
+    if d.Links.Hardlinks != nil && *d.Links.Hardlinks > 1 && !d.IsDir { b.kv("Links", fmt.Sprintf("hardlinks: %d", *d.Links.Hardlinks)) }
+    if d.About != "" { b.kv("About", d.About) }
+    ft := d.Filetype
+    if ft.Pages != nil { b.kv("Pages", fmt.Sprintf("%d", *ft.Pages)) }
+    if ft.ImageDims != "" { b.kv("Image", ft.ImageDims) }
+    if ft.Headings != nil { b.kv("Headings", fmt.Sprintf("%d", *ft.Headings)) }
+    if ft.Columns != nil { b.kv("Columns", fmt.Sprintf("%d %s", *ft.Columns, b.dim("(delimiter: "+ft.Delimiter+")"))) }
+    if d.Git.Present {
+        g := d.Git.Branch
+        if d.Git.Status != "" { g += " " + b.dim("("+d.Git.Status+")") }
+        b.kv("Git", g)
+    }
+
+    if long {
+        // Timeline
+        b.section(secTimeline)
+        ago := func(e *int64) string {
+            if e == nil || *e <= 0 { return "" }
+            return " " + b.dim("("+fmtAgo(now.Sub(time.Unix(*e, 0)))+")")
+        }
+        created := d.Dates.Created
+        if created == "" { created = "–" }
+        modified := d.Dates.Modified
+        if modified == "" { modified = "–" }
+        b.kv("Created", created+ago(d.Dates.CreatedEpoch))
+        b.kv("Modified", modified+ago(d.Dates.ModifiedEpoch))
+        if d.Dates.Accessed != "" { b.kv("Accessed", d.Dates.Accessed+ago(d.Dates.AccessedEpoch)) }
+
+        // Paths
+        b.section(secPaths)
+        b.kv("Rel", d.Path.Rel)
+        b.kv("Abs", ellipsizeMiddle(d.Path.Abs, width-16))
+        if d.Symlink.IsSymlink == 1 {
+            if d.Symlink.TargetExists == 1 { b.kv("Symlink", d.Symlink.Target) } else { b.kv("Symlink", d.Symlink.Target+" (missing)") }
+        }
+
+        // Security & provenance
+        b.section(secSecurity)
+        sec := d.Security
+        verdict := sec.Verdict
+        if verdict == "" { verdict = "unknown" }
+        chip := lipgloss.NewStyle()
+        switch verdict {
+        case "safe": chip = chip.Foreground(lipgloss.Color("2"))
+        case "caution": chip = chip.Foreground(lipgloss.Color("3"))
+        case "unsafe": chip = chip.Foreground(lipgloss.Color("1"))
+        }
+        detail := "gatekeeper:" + sec.Gatekeeper + " codesign:" + sec.Codesign.Status
+        if sec.Codesign.Team != "" { detail += " team:" + sec.Codesign.Team }
+        if sec.Notarization != "" { detail += " notarization:" + sec.Notarization }
+        b.kv("Verdict", chip.Render("["+verdict+"]")+" "+b.dim("("+detail+")"))
+        if sec.Quarantine == "yes" { b.kv("Quarantine", lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Render("yes")) }
+        if sec.WhereFroms != "" { b.kv("WhereFroms", sec.WhereFroms) }
+    }
+
+    // Actions (deduplicated, order preserved)
+    seen := map[string]bool{}
+    acts := make([]string, 0, len(d.Quality)+len(d.Actions))
+    for _, a := range append(append([]string{}, d.Quality...), d.Actions...) {
+        if a == "" || seen[a] { continue }
+        seen[a] = true
+        acts = append(acts, a)
+    }
+    if len(acts) > 0 {
+        b.section(secActions)
+        for _, a := range acts { b.line("    " + b.th.path.Render(a)) }
+    }
+
+    // Tips
+    tip := ""
+    if d.IsDir { tip = "use 'll' for detailed listing" } else if inspect.HaveTool("bat") { tip = "prefer 'bat' over 'cat' for syntax highlighting" }
+    if tip != "" {
+        b.section(secTips)
+        b.line("    " + b.th.path.Render(tip))
+    }
+    return strings.Join(b.lines, "\n"), b.offs
+}
+
+// noSections is used when the preview shows raw text instead of a document.
+func noSections() sectionOffsets {
+    var o sectionOffsets
+    for i := range o { o[i] = -1 }
+    return o
+}
+
+// jumpToSection scrolls the preview to s, or the nearest following section
+// when s is hidden (e.g. Timeline in brief mode).
+func (m *model) jumpToSection(s previewSection) {
+    for i := s; i < numSections; i++ {
+        if off := m.sections[i]; off >= 0 { m.preview.SetYOffset(off); return }
+    }
+    m.status = strings.ToLower(sectionTitles[s]) + " not shown"
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    tea "github.com/charmbracelet/bubbletea"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+)
+
+// ---------- Async preview loading ----------
+
+// previewState is the per-item load state shown next to list entries.
+type previewState int
+
+const (
+    previewIdle previewState = iota
+    previewLoading
+    previewCancelled
+    previewTimedOut
+    previewFailed
+)
+
+func (s previewState) String() string {
+    switch s {
+    case previewLoading: return "loading…"
+    case previewCancelled: return "cancelled"
+    case previewTimedOut: return "timed out"
+    case previewFailed: return "error"
+    }
+    return ""
+}
+
+// previewTickMsg fires after the debounce delay; the load only starts if no
+// newer selection happened in between.
+type previewTickMsg struct{ seq int }
+
+// previewMsg carries a finished preview. Everything expensive (inspection,
+// subprocesses, JSON decoding) happens before it reaches Update.
+type previewMsg struct {
+    seq      int
+    path     string
+    doc      *inspect.Document
+    raw      string // JSON document, for "Copy JSON"
+    text     string // pretty/raw fallback when no document could be decoded
+    state    previewState
+    err      string
+    engine   string // what produced the preview: native, shell or cache
+    fallback string // why the native inspector was not used, if it failed
+}
+
+// schedulePreview cancels the in-flight preview and debounces the next one.
+func (m *model) schedulePreview() tea.Cmd {
+    m.cancelPreview()
+    m.previewSeq++
+    seq := m.previewSeq
+    return tea.Tick(m.previewDelay, func(time.Time) tea.Msg { return previewTickMsg{seq: seq} })
+}
+
+// loadPreview starts a preview for the selected item immediately.
+func (m *model) loadPreview() tea.Cmd {
+    m.cancelPreview()
+    m.previewSeq++
+    return m.startPreview(m.previewSeq)
+}
+
+// cancelPreview stops the in-flight preview (killing any subprocess) and
+// marks its item as cancelled.
+func (m *model) cancelPreview() {
+    if m.previewCancel == nil { return }
+    m.previewCancel()
+    m.previewCancel = nil
+    if m.previewStates[m.previewPath] == previewLoading { m.previewStates[m.previewPath] = previewCancelled }
+}
+
+func (m *model) startPreview(seq int) tea.Cmd {
+    if !m.showPreview { return nil }
+    it, ok := m.files.selectedItem()
+    if !ok { return nil }
+    ctx, cancel := context.WithTimeout(context.Background(), m.previewTimeout)
+    m.previewCancel = cancel
+    m.previewPath = it.path
+    m.previewStates[it.path] = previewLoading
+    if m.lastDoc == nil || m.lastDoc.Path.Rel != it.path { m.preview.SetContent("Loading… " + it.path) }
+    path, long, engine, cache := it.path, m.long, m.engine, m.cache
+    return func() tea.Msg {
+        defer cancel()
+        msg := fetchPreview(ctx, cache, path, long, engine)
+        msg.seq = seq
+        return msg
+    }
+}
+
+// fetchPreview runs on a Cmd goroutine. Cached documents are returned
+// without touching the engine; the shell engine falls back to the pretty
+// output within the same context when the JSON cannot be decoded.
+func fetchPreview(ctx context.Context, cache *previewCache, path string, long bool, engine string) previewMsg {
+    if doc, raw, ok := cache.get(path, long); ok { return previewMsg{path: path, doc: doc, raw: raw, engine: "cache"} }
+    return runPreview(ctx, cache, path, long, engine)
+}
+
+// runPreview inspects path with the selected engine and stores successful
+// documents in the cache; when the native inspector fails it falls back to
+// finfo.zsh. Also used by the prefetch workers.
+func runPreview(ctx context.Context, cache *previewCache, path string, long bool, engine string) previewMsg {
+    msg := previewMsg{path: path}
+    finish := func(err error) previewMsg {
+        switch {
+        case errors.Is(ctx.Err(), context.DeadlineExceeded): msg.state = previewTimedOut; msg.err = "timed out"
+        case errors.Is(ctx.Err(), context.Canceled): msg.state = previewCancelled; msg.err = "cancelled"
+        case err != nil: msg.state = previewFailed; msg.err = err.Error()
+        }
+        return msg
+    }
+    if engine != "shell" {
+        doc, err := inspect.InspectContext(ctx, path, inspect.Options{Long: long, GitStatus: long})
+        if err == nil {
+            b, merr := json.Marshal(doc)
+            if err = merr; err == nil {
+                msg.doc, msg.raw, msg.engine = doc, string(b), "native"
+                cache.put(path, long, doc, msg.raw)
+                return finish(nil)
+            }
+        }
+        if ctx.Err() != nil { return finish(err) }
+        msg.fallback = err.Error()
+    }
+    msg.engine = "shell"
+    args := finfoPreviewArgs(path, long)
+    out, err := runCmdTimeout(ctx, args[0], args[1:]...)
+    if doc, derr := inspect.Decode([]byte(out), inspect.Lenient); derr == nil {
+        msg.doc = doc
+        if i, j := strings.Index(out, "{"), strings.LastIndex(out, "}"); i >= 0 && j > i { msg.raw = out[i:j+1] }
+        if err == nil { cache.put(path, long, doc, msg.raw) }
+        return finish(nil)
+    }
+    if ctx.Err() != nil { return finish(err) }
+    pargs := finfoPrettyArgs(path, long)
+    pretty, perr := runCmdTimeout(ctx, pargs[0], pargs[1:]...)
+    if strings.TrimSpace(pretty) != "" { msg.text = pretty } else { msg.text = out }
+    if err == nil { err = perr }
+    if err != nil && msg.fallback != "" { err = fmt.Errorf("native: %s; finfo.zsh: %w", msg.fallback, err) }
+    return finish(err)
+}
+
+// applyPreview installs a finished preview if it is still the latest one.
+func (m *model) applyPreview(msg previewMsg) {
+    if msg.seq != m.previewSeq { return }
+    m.previewCancel = nil
+    if msg.state == previewIdle { delete(m.previewStates, msg.path) } else { m.previewStates[msg.path] = msg.state }
+    switch {
+    case msg.doc != nil:
+        m.lastDoc = msg.doc
+        m.lastPreviewRaw = msg.raw
+        content, offs := renderPreview(msg.doc, m.long, m.preview.Width, m.theme)
+        m.sections = offs
+        m.preview.SetContent(content)
+    case msg.text != "":
+        m.lastDoc = nil
+        m.lastPreviewRaw = ""
+        m.sections = noSections()
+        m.preview.SetContent(msg.text)
+    default:
+        m.lastDoc = nil
+        m.sections = noSections()
+        m.preview.SetContent(msg.path + "\n\npreview " + msg.state.String())
+    }
+    m.previewEngine = msg.engine
+    switch {
+    case msg.err != "": m.status = "preview " + msg.err
+    case msg.fallback != "": m.status = "native inspector failed (" + msg.fallback + "); preview from finfo.zsh"
+    default: m.status = ""
+    }
+}
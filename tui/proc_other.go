@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// killProcessGroup falls back to exec.CommandContext's default (kill the
+// direct child only) where process groups are unavailable.
+func killProcessGroup(c *exec.Cmd) {}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package main
+
+import (
+    "os/exec"
+    "syscall"
+)
+
+// killProcessGroup makes ctx cancellation kill the whole process group, so
+// helpers spawned by finfo.zsh (file, git, mdls…) die with it.
+func killProcessGroup(c *exec.Cmd) {
+    c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+    c.Cancel = func() error {
+        if c.Process == nil { return nil }
+        return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+    }
+}
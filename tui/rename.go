@@ -0,0 +1,367 @@
+package main
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "regexp"
+    "runtime"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+    "unicode/utf8"
+)
+
+// ---------- Rename patterns ----------
+
+// A rename pattern is a template for the new base name:
+//
+//   {name} {ext}           base name without extension, extension with its dot
+//   {n} {n:3} {n:3:10:5}   counter: zero-padded width, start, step
+//   {mtime} {mtime:YYYYMMDD_hhmmss}  modification time (default YYYY-MM-DD);
+//                          YYYY YY MM DD hh mm ss, other text is literal
+//   {date} {date:…}        EXIF DateTimeOriginal, else the modification time
+//   {0} {1} …              regular expression match and capture groups
+//
+// Any token takes transforms: {name|lower}, {1|title}, {ext|upper}.
+// "/regex/template" replaces only the first match of regex in each name;
+// names it does not match stay as they are.
+
+type renamePart struct {
+    lit   string
+    token string // empty for literal text
+    arg   string
+    convs []string
+}
+
+type renameSpec struct {
+    re       *regexp.Regexp
+    parts    []renamePart
+    width    int // counter
+    start    int
+    step     int
+    needExif bool
+}
+
+var renameConvs = map[string]func(string) string{"upper": strings.ToUpper, "lower": strings.ToLower, "title": titleCase}
+
+func parseRenameSpec(s string) (*renameSpec, error) {
+    r := &renameSpec{start: 1, step: 1}
+    if strings.HasPrefix(s, "/") {
+        end := -1
+        for i := 1; i < len(s); i++ {
+            if s[i] == '\\' { i++; continue }
+            if s[i] == '/' { end = i; break }
+        }
+        if end < 0 { return nil, fmt.Errorf("regex needs a closing /: /regex/template") }
+        re, err := regexp.Compile(strings.ReplaceAll(s[1:end], `\/`, "/"))
+        if err != nil { return nil, fmt.Errorf("regex: %v", err) }
+        r.re, s = re, s[end+1:]
+    }
+    for s != "" {
+        i := strings.IndexByte(s, '{')
+        if i < 0 { r.parts = append(r.parts, renamePart{lit: s}); break }
+        if i > 0 { r.parts = append(r.parts, renamePart{lit: s[:i]}) }
+        j := strings.IndexByte(s[i:], '}')
+        if j < 0 { return nil, fmt.Errorf("unclosed { in %q", s[i:]) }
+        p, err := r.parseToken(s[i+1 : i+j])
+        if err != nil { return nil, err }
+        r.parts = append(r.parts, p)
+        s = s[i+j+1:]
+    }
+    return r, nil
+}
+
+func (r *renameSpec) parseToken(t string) (renamePart, error) {
+    fields := strings.Split(t, "|")
+    p := renamePart{token: fields[0], convs: fields[1:]}
+    for _, c := range p.convs {
+        if renameConvs[c] == nil { return p, fmt.Errorf("unknown transform |%s (upper, lower, title)", c) }
+    }
+    if k := strings.IndexByte(p.token, ':'); k >= 0 { p.token, p.arg = p.token[:k], p.token[k+1:] }
+    switch p.token {
+    case "name", "ext":
+    case "n":
+        for k, f := range strings.Split(p.arg, ":") {
+            if f == "" { continue }
+            v, err := strconv.Atoi(f)
+            if err != nil || k > 2 { return p, fmt.Errorf("counter is {n:width:start:step}, got {%s}", t) }
+            switch k {
+            case 0: r.width = v
+            case 1: r.start = v
+            case 2: r.step = v
+            }
+        }
+    case "mtime", "date":
+        if p.arg == "" { p.arg = "YYYY-MM-DD" }
+        r.needExif = r.needExif || p.token == "date"
+    default:
+        g, err := strconv.Atoi(p.token)
+        if err != nil { return p, fmt.Errorf("unknown token {%s}", t) }
+        if r.re == nil && g > 0 { return p, fmt.Errorf("{%d} needs a /regex/ with groups", g) }
+        if r.re != nil && g > r.re.NumSubexp() { return p, fmt.Errorf("{%d}: the regex has %d group(s)", g, r.re.NumSubexp()) }
+    }
+    return p, nil
+}
+
+// formatDate expands YYYY YY MM DD hh mm ss in f; everything else,
+// including digits and words a Go layout would read as fields, is copied
+// as it is.
+func formatDate(t time.Time, f string) string {
+    b := &strings.Builder{}
+    for i := 0; i < len(f); {
+        switch {
+        case strings.HasPrefix(f[i:], "YYYY"): fmt.Fprintf(b, "%04d", t.Year()); i += 4
+        case strings.HasPrefix(f[i:], "YY"): fmt.Fprintf(b, "%02d", t.Year()%100); i += 2
+        case strings.HasPrefix(f[i:], "MM"): fmt.Fprintf(b, "%02d", int(t.Month())); i += 2
+        case strings.HasPrefix(f[i:], "DD"): fmt.Fprintf(b, "%02d", t.Day()); i += 2
+        case strings.HasPrefix(f[i:], "hh"): fmt.Fprintf(b, "%02d", t.Hour()); i += 2
+        case strings.HasPrefix(f[i:], "mm"): fmt.Fprintf(b, "%02d", t.Minute()); i += 2
+        case strings.HasPrefix(f[i:], "ss"): fmt.Fprintf(b, "%02d", t.Second()); i += 2
+        default: b.WriteByte(f[i]); i++
+        }
+    }
+    return b.String()
+}
+
+// apply returns the new base name for path, the idx-th target. ok is false
+// when a regex is set and does not match.
+func (r *renameSpec) apply(path string, idx int) (name string, ok bool, err error) {
+    base := filepath.Base(path)
+    ext := filepath.Ext(base)
+    var mtime, date time.Time
+    for _, p := range r.parts {
+        if p.token != "mtime" && p.token != "date" { continue }
+        if mtime.IsZero() {
+            fi, err := os.Stat(path)
+            if err != nil { return "", false, err }
+            mtime, date = fi.ModTime(), fi.ModTime()
+            if r.needExif {
+                if t, ok := exifTime(path); ok { date = t }
+            }
+        }
+    }
+    groups := []string{base}
+    head, tail := "", ""
+    if r.re != nil {
+        loc := r.re.FindStringSubmatchIndex(base)
+        if loc == nil { return base, false, nil }
+        groups = make([]string, len(loc)/2)
+        for g := range groups {
+            if loc[2*g] >= 0 { groups[g] = base[loc[2*g]:loc[2*g+1]] }
+        }
+        head, tail = base[:loc[0]], base[loc[1]:]
+    }
+    b := &strings.Builder{}
+    b.WriteString(head)
+    for _, p := range r.parts {
+        if p.token == "" { b.WriteString(p.lit); continue }
+        v := ""
+        switch p.token {
+        case "name": v = strings.TrimSuffix(base, ext)
+        case "ext": v = ext
+        case "n": v = fmt.Sprintf("%0*d", r.width, r.start+idx*r.step)
+        case "mtime": v = formatDate(mtime, p.arg)
+        case "date": v = formatDate(date, p.arg)
+        default:
+            g, _ := strconv.Atoi(p.token)
+            v = groups[g]
+        }
+        for _, c := range p.convs { v = renameConvs[c](v) }
+        b.WriteString(v)
+    }
+    b.WriteString(tail)
+    return b.String(), true, nil
+}
+
+// titleCase capitalises the first letter of each word; words are split on
+// spaces, dashes, underscores and dots.
+func titleCase(s string) string {
+    out := []rune(strings.ToLower(s))
+    start := true
+    for i, c := range out {
+        if start && unicode.IsLetter(c) { out[i] = unicode.ToUpper(c) }
+        start = c == ' ' || c == '-' || c == '_' || c == '.'
+    }
+    return string(out)
+}
+
+// ---------- EXIF dates ----------
+
+// exifTime reads DateTimeOriginal (or DateTime) from the EXIF block of a
+// JPEG or a TIFF-based raw file.
+func exifTime(p string) (time.Time, bool) {
+    f, err := os.Open(p)
+    if err != nil { return time.Time{}, false }
+    defer f.Close()
+    buf := make([]byte, 128<<10)
+    n, _ := io.ReadFull(f, buf)
+    b := buf[:n]
+    switch {
+    case len(b) > 8 && (string(b[:4]) == "II*\x00" || string(b[:4]) == "MM\x00*"):
+        return tiffTime(b)
+    case len(b) > 4 && b[0] == 0xFF && b[1] == 0xD8:
+        for i := 2; i+4 <= len(b) && b[i] == 0xFF; {
+            marker, size := b[i+1], int(binary.BigEndian.Uint16(b[i+2:]))
+            if marker == 0xDA || size < 2 { break } // image data starts
+            seg := b[i+4 : min(i+2+size, len(b))]
+            if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) { return tiffTime(seg[6:]) }
+            i += 2 + size
+        }
+    }
+    return time.Time{}, false
+}
+
+func tiffTime(t []byte) (time.Time, bool) {
+    if len(t) < 8 { return time.Time{}, false }
+    var bo binary.ByteOrder = binary.LittleEndian
+    if t[0] == 'M' { bo = binary.BigEndian }
+    // entry finds tag in the IFD at off: its count and value (or offset).
+    entry := func(off, tag uint32) (count, val uint32, ok bool) {
+        if int(off)+2 > len(t) { return 0, 0, false }
+        n := uint32(bo.Uint16(t[off:]))
+        for k := uint32(0); k < n; k++ {
+            e := off + 2 + 12*k
+            if int(e)+12 > len(t) { break }
+            if uint32(bo.Uint16(t[e:])) == tag { return bo.Uint32(t[e+4:]), bo.Uint32(t[e+8:]), true }
+        }
+        return 0, 0, false
+    }
+    stamp := func(count, val uint32) (time.Time, bool) {
+        if count < 19 || int(val)+19 > len(t) { return time.Time{}, false }
+        tm, err := time.ParseInLocation("2006:01:02 15:04:05", string(t[val:val+19]), time.Local)
+        return tm, err == nil
+    }
+    ifd0 := bo.Uint32(t[4:])
+    if _, sub, ok := entry(ifd0, 0x8769); ok {
+        if c, v, ok := entry(sub, 0x9003); ok {
+            if tm, ok := stamp(c, v); ok { return tm, true }
+        }
+    }
+    if c, v, ok := entry(ifd0, 0x0132); ok { return stamp(c, v) }
+    return time.Time{}, false
+}
+
+// ---------- Rename plans ----------
+
+// planRename expands the pattern over the targets and shows the plan with
+// each row checked; Enter is refused while any row has a problem.
+func (m *model) planRename(pat string) error {
+    spec, err := parseRenameSpec(pat)
+    if err != nil { return err }
+    targets := m.targetItems()
+    paths := make([]string, len(targets))
+    for i, t := range targets { paths[i] = t.path }
+    ops, bad, same, nomatch, err := expandRename(spec, paths)
+    if err != nil { return err }
+    summary := ""
+    if same > 0 { summary += fmt.Sprintf(" · %d unchanged", same) }
+    if nomatch > 0 { summary += fmt.Sprintf(" · %d not matched", nomatch) }
+    m.showRenamePlan(actRenamePattern, "Rename preview  "+pat, summary, ops, bad)
+    return nil
+}
+
+// expandRename applies spec to paths. A pattern makes base names, so a
+// name with a separator in it is flagged in bad rather than read as a path.
+func expandRename(spec *renameSpec, paths []string) (ops []op, bad []string, same, nomatch int, err error) {
+    for idx, p := range paths {
+        name, ok, err := spec.apply(p, idx)
+        if err != nil { return nil, nil, 0, 0, err }
+        switch {
+        case !ok: nomatch++
+        case name == filepath.Base(p): same++
+        default:
+            ops = append(ops, op{from: p, to: filepath.Join(filepath.Dir(p), name)})
+            issue := ""
+            if msg := badName(name); msg != "" { issue = "illegal name: " + msg }
+            bad = append(bad, issue)
+        }
+    }
+    return ops, bad, same, nomatch, nil
+}
+
+// showRenamePlan puts ops in the dry-run overlay, each row checked; e goes
+// back to editing the pattern or the list. bad holds problems already found
+// per op, if any.
+func (m *model) showRenamePlan(act action, title, summary string, ops []op, bad []string) {
+    issues := checkRenames(ops)
+    for i, s := range bad {
+        if s != "" { issues[i] = s }
+    }
+    m.renameBad = 0
+    for _, s := range issues {
+        if s != "" { m.renameBad++ }
+    }
+    b := &strings.Builder{}
+    fmt.Fprintf(b, "%s\n%d to rename%s", title, len(ops), summary)
+    if m.renameBad > 0 { fmt.Fprintf(b, " · %d problem(s)", m.renameBad) }
+    b.WriteString("\n\n")
+    for i, op := range ops {
+        mark := "  "
+        if issues[i] != "" { mark = "✗ " }
+        to := op.to
+        if filepath.Dir(to) == filepath.Dir(op.from) { to = filepath.Base(to) }
+        fmt.Fprintf(b, "%s%s\n  ↳ %s", mark, op.from, to)
+        if issues[i] != "" { fmt.Fprintf(b, "   (%s)", issues[i]) }
+        b.WriteString("\n\n")
+    }
+    if m.renameBad > 0 { b.WriteString("e to edit again; enter is blocked until every row is clear") } else { b.WriteString("enter renames · e to edit again · esc cancels") }
+    m.pendingOps = ops
+    m.pendingAct = act
+    m.opsOverlayText = b.String()
+    m.opsOverlay.SetContent(m.opsOverlayText)
+    m.mode = modeOpsPreview
+    m.status = "enter to confirm, esc to cancel"
+    if m.renameBad > 0 { m.status = fmt.Sprintf("%d problem(s) in the rename plan", m.renameBad) }
+}
+
+// checkRenames flags, per op, names the filesystem would refuse, two ops
+// with the same destination and destinations that already exist. A
+// destination another op moves away is fine: see orderRenames.
+func checkRenames(ops []op) []string {
+    issues := make([]string, len(ops))
+    first := map[string]int{}
+    moving := map[string]bool{}
+    for _, op := range ops { moving[op.from] = true }
+    for i, op := range ops {
+        if msg := badName(filepath.Base(op.to)); msg != "" { issues[i] = "illegal name: " + msg; continue }
+        if fi, err := os.Stat(filepath.Dir(op.to)); err != nil || !fi.IsDir() { issues[i] = "no such directory"; continue }
+        key := op.to
+        if runtime.GOOS == "darwin" || runtime.GOOS == "windows" { key = strings.ToLower(key) } // case-insensitive by default
+        if k, dup := first[key]; dup {
+            issues[i] = fmt.Sprintf("same name as %s", filepath.Base(ops[k].from))
+            if issues[k] == "" { issues[k] = fmt.Sprintf("same name as %s", filepath.Base(op.from)) }
+            continue
+        }
+        first[key] = i
+        if moving[op.to] { continue }
+        if dfi, err := os.Lstat(op.to); err == nil {
+            if sfi, err := os.Lstat(op.from); err == nil && os.SameFile(sfi, dfi) { continue } // only the case changes
+            issues[i] = "would overwrite an existing entry"
+        }
+    }
+    return issues
+}
+
+// badName says why name cannot be a file name here, or returns "".
+func badName(name string) string {
+    switch {
+    case name == "", name == ".", name == "..": return fmt.Sprintf("%q", name)
+    case strings.ContainsAny(name, "/\x00"): return "contains / or NUL"
+    case len(name) > 255: return "longer than 255 bytes"
+    case !utf8.ValidString(name): return "not valid UTF-8"
+    }
+    if runtime.GOOS == "windows" {
+        if strings.ContainsAny(name, `<>:"\|?*`) { return `contains one of <>:"\|?*` }
+        if strings.HasSuffix(name, " ") || strings.HasSuffix(name, ".") { return "ends in a space or dot" }
+        stem := strings.ToUpper(strings.TrimSuffix(name, filepath.Ext(name)))
+        switch stem {
+        case "CON", "PRN", "AUX", "NUL", "COM1", "COM2", "COM3", "COM4", "LPT1", "LPT2", "LPT3": return "reserved on Windows"
+        }
+    }
+    return ""
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestFormatDate(t *testing.T) {
+    tm := time.Date(2024, time.March, 9, 7, 5, 3, 0, time.Local)
+    tests := []struct{ f, want string }{
+        {"YYYY-MM-DD", "2024-03-09"},
+        {"YYYYMMDD_hhmmss", "20240309_070503"},
+        {"DD.MM.YY", "09.03.24"},
+        {"YYYY_v2", "2024_v2"},
+        {"Jan Mon 1 2 PM", "Jan Mon 1 2 PM"},
+        {"YYYY-MM-DD 15:04", "2024-03-09 15:04"},
+        {"Y M D h m s", "Y M D h m s"},
+        {"", ""},
+    }
+    for _, tt := range tests {
+        if got := formatDate(tm, tt.f); got != tt.want { t.Errorf("formatDate(%q) = %q, want %q", tt.f, got, tt.want) }
+    }
+}
+
+func TestRenameSpec(t *testing.T) {
+    dir := t.TempDir()
+    mtime := time.Date(2023, time.December, 31, 23, 59, 58, 0, time.Local)
+    file := func(name string) string {
+        p := filepath.Join(dir, name)
+        if _, err := os.Lstat(p); err != nil {
+            os.WriteFile(p, nil, 0o644)
+            os.Chtimes(p, mtime, mtime)
+        }
+        return p
+    }
+    tests := []struct {
+        pat    string
+        file   string
+        idx    int
+        want   string
+        wantOK bool
+    }{
+        {"{name}_{n:3}{ext}", "a.txt", 0, "a_001.txt", true},
+        {"{name}_{n:2:10:5}{ext}", "a.txt", 4, "a_30.txt", true},
+        {"{name|upper}{ext}", "a.txt", 0, "A.txt", true},
+        {"{name|title}{ext|upper}", "hello-world_foo.txt", 0, "Hello-World_Foo.TXT", true},
+        {"{mtime}{ext}", "a.txt", 0, "2023-12-31.txt", true},
+        {"{mtime:YYYYMMDD_hhmmss}_v2{ext}", "a.txt", 0, "20231231_235958_v2.txt", true},
+        {"{date:YY} Jan 1{ext}", "a.txt", 0, "23 Jan 1.txt", true},
+        {"/(\\d+)-(\\w+)/{2}-{1}", "12-ab.txt", 0, "ab-12.txt", true},
+        {"/a/b", "banana", 0, "bbnana", true},
+        {"/(na)+/{0|upper}", "banana", 0, "baNANA", true},
+        {"/x\\/y/z", "a.txt", 0, "a.txt", false},
+        {"/^IMG_/photo-", "IMG_0001.jpg", 0, "photo-0001.jpg", true},
+        {"/^IMG_/photo-", "a.txt", 0, "a.txt", false},
+    }
+    for _, tt := range tests {
+        spec, err := parseRenameSpec(tt.pat)
+        if err != nil { t.Errorf("parseRenameSpec(%q): %v", tt.pat, err); continue }
+        got, ok, err := spec.apply(file(tt.file), tt.idx)
+        if err != nil || ok != tt.wantOK || got != tt.want {
+            t.Errorf("%q on %s = %q, %v, %v; want %q, %v", tt.pat, tt.file, got, ok, err, tt.want, tt.wantOK)
+        }
+    }
+}
+
+func TestRenameSpecErrors(t *testing.T) {
+    for _, pat := range []string{"{bogus}", "{name|shout}", "{1}", "/(a)/{2}", "/unclosed", "{name", "{n:x}", "{n:1:2:3:4}", "/(/x"} {
+        if _, err := parseRenameSpec(pat); err == nil { t.Errorf("parseRenameSpec(%q) succeeded", pat) }
+    }
+}
+
+func TestExpandRename(t *testing.T) {
+    dir := t.TempDir()
+    var paths []string
+    for _, n := range []string{"a.txt", "b.txt", "c.md"} {
+        p := filepath.Join(dir, n)
+        os.WriteFile(p, nil, 0o644)
+        paths = append(paths, p)
+    }
+    tests := []struct {
+        pat     string
+        issues  []string // substring per op, "" for a clean row
+        same    int
+        nomatch int
+    }{
+        {"{name}{ext}", nil, 3, 0},
+        {"/\\.txt$/.md", []string{"", ""}, 0, 1},
+        {"{name}/x{ext}", []string{"contains /", "contains /", "contains /"}, 0, 0},
+        {"/.*/", []string{`""`, `""`, `""`}, 0, 0}, // empty names
+    }
+    for _, tt := range tests {
+        spec, err := parseRenameSpec(tt.pat)
+        if err != nil { t.Fatalf("%q: %v", tt.pat, err) }
+        ops, bad, same, nomatch, err := expandRename(spec, paths)
+        if err != nil { t.Fatalf("%q: %v", tt.pat, err) }
+        if same != tt.same || nomatch != tt.nomatch { t.Errorf("%q: same %d nomatch %d, want %d %d", tt.pat, same, nomatch, tt.same, tt.nomatch) }
+        if len(ops) != len(tt.issues) || len(bad) != len(ops) { t.Errorf("%q: %d ops, %d issues; want %d", tt.pat, len(ops), len(bad), len(tt.issues)); continue }
+        for i, want := range tt.issues {
+            if (want == "") != (bad[i] == "") || !strings.Contains(bad[i], want) { t.Errorf("%q: op %d issue %q, want %q", tt.pat, i, bad[i], want) }
+        }
+    }
+}
+
+func TestCheckRenames(t *testing.T) {
+    dir := t.TempDir()
+    p := func(s string) string { return filepath.Join(dir, s) }
+    for _, n := range []string{"a", "b", "c", "taken"} { os.WriteFile(p(n), nil, 0o644) }
+    tests := []struct {
+        name   string
+        ops    []op
+        issues []string // substring per op, "" for a clean row
+    }{
+        {"plain", []op{{p("a"), p("x")}}, []string{""}},
+        {"collision", []op{{p("a"), p("x")}, {p("b"), p("x")}}, []string{"same name as b", "same name as a"}},
+        {"overwrite", []op{{p("a"), p("taken")}}, []string{"overwrite"}},
+        {"swap", []op{{p("a"), p("b")}, {p("b"), p("a")}}, []string{"", ""}},
+        {"chain onto a moved entry", []op{{p("a"), p("b")}, {p("b"), p("c")}, {p("c"), p("d")}}, []string{"", "", ""}},
+        {"missing directory", []op{{p("a"), p("nope/a")}}, []string{"no such directory"}},
+        {"dot name", []op{{p("a"), dir + "/.."}}, []string{"illegal name"}},
+    }
+    for _, tt := range tests {
+        got := checkRenames(tt.ops)
+        for i, want := range tt.issues {
+            if (want == "") != (got[i] == "") || !strings.Contains(got[i], want) { t.Errorf("%s: op %d issue %q, want %q", tt.name, i, got[i], want) }
+        }
+    }
+}
+
+func TestBadName(t *testing.T) {
+    for _, name := range []string{"", ".", "..", "a/b", "a\x00b", strings.Repeat("x", 256), "\xff"} {
+        if badName(name) == "" { t.Errorf("badName(%q) accepted", name) }
+    }
+    for _, name := range []string{"a", ".hidden", "name with spaces", "ünïcode.txt"} {
+        if msg := badName(name); msg != "" { t.Errorf("badName(%q) = %q", name, msg) }
+    }
+}
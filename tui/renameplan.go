@@ -0,0 +1,108 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Rename plans ----------
+
+// Renames and moves run as one transaction: the ops are put in an order in
+// which no step lands on a path that is still to be moved away, cycles go
+// through a temporary name, and the steps run one after another. The first
+// failure rolls back the steps already done, so a plan is applied entirely
+// or not at all, and the steps are journalled as one undo batch.
+
+// orderRenames orders ops so none lands on a path another op has yet to
+// move away. Cycles (a↔b, a→b→c→a) are broken by first moving one entry to
+// a temporary name next to it.
+func orderRenames(ops []op) []op {
+    rest := append([]op(nil), ops...)
+    out := make([]op, 0, len(ops)+1)
+    for len(rest) > 0 {
+        from := map[string]bool{}
+        for _, o := range rest { from[o.from] = true }
+        k := -1
+        for i, o := range rest {
+            if !from[o.to] || o.to == o.from { k = i; break }
+        }
+        if k < 0 {
+            tmp := tempName(rest[0].from)
+            out = append(out, op{from: rest[0].from, to: tmp})
+            rest[0].from = tmp
+            continue
+        }
+        out = append(out, rest[k])
+        rest = append(rest[:k], rest[k+1:]...)
+    }
+    return out
+}
+
+// tempName is an unused hidden name next to p.
+func tempName(p string) string {
+    dir, base := filepath.Dir(p), filepath.Base(p)
+    for n := 0; ; n++ {
+        try := filepath.Join(dir, fmt.Sprintf(".%s.finfo-rename-%d", base, n))
+        if _, err := os.Lstat(try); os.IsNotExist(err) { return try }
+    }
+}
+
+// renameStep moves from to to, refusing to replace anything: os.Rename
+// would silently overwrite a file. A destination that is the source itself
+// (a case-only rename on a case-insensitive filesystem) is fine.
+func renameStep(ctx context.Context, s op) (string, error) {
+    if dfi, err := os.Lstat(s.to); err == nil {
+        sfi, serr := os.Lstat(s.from)
+        if serr != nil || !os.SameFile(sfi, dfi) { return "", fmt.Errorf("%s: already exists", s.to) }
+    }
+    return moveEntry(ctx, s.from, s.to)
+}
+
+// renameJob applies ops as one transaction, see above.
+func renameJob(act action, batch int64, ops []op) jobFunc {
+    return func(ctx context.Context, j *job) (tea.Msg, error) {
+        steps := orderRenames(ops)
+        j.total.Store(int64(len(steps)))
+        msg := batchDoneMsg{act: act, batch: batch}
+        var err error
+        k := 0
+        for ; k < len(steps); k++ {
+            if err = ctx.Err(); err != nil { break }
+            note, serr := renameStep(ctx, steps[k])
+            if note != "" { j.notes = append(j.notes, note) }
+            if serr != nil { err = serr; break }
+            j.n.Add(1)
+        }
+        if err == nil {
+            for _, s := range steps { msg.recs = append(msg.recs, journalOp{Kind: "move", From: s.from, To: s.to}) }
+            msg.n = len(ops)
+            return msg, nil
+        }
+        // Roll back in reverse, even when cancelled; what cannot be rolled
+        // back stays journalled so it can still be undone by hand.
+        undone := 0
+        for r := k - 1; r >= 0; r-- {
+            s := steps[r]
+            if _, rerr := renameStep(context.Background(), op{from: s.to, to: s.from}); rerr != nil {
+                j.notes = append(j.notes, "rollback failed: "+rerr.Error())
+                for _, s := range steps[:r+1] { msg.recs = append(msg.recs, journalOp{Kind: "move", From: s.from, To: s.to}) }
+                return msg, fmt.Errorf("%w; rollback failed, %d step(s) left applied (U undoes them)", err, r+1)
+            }
+            undone++
+        }
+        if undone > 0 { j.notes = append(j.notes, fmt.Sprintf("rolled back %d step(s)", undone)) }
+        return msg, fmt.Errorf("%w; nothing was changed", err)
+    }
+}
+
+// startRenames queues a rename or move plan as one transaction.
+func (m *model) startRenames(act action, ops []op) tea.Cmd {
+    if len(ops) == 0 { return nil }
+    paths := make([]string, len(ops))
+    for i, o := range ops { paths[i] = o.from }
+    return m.startJob(actionVerbs[act], targetLabel(paths), renameJob(act, m.journal.newBatch(), ops))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// simulate runs steps over a set of names, failing when a step's source is
+// missing or its destination is taken.
+func simulate(t *testing.T, names map[string]string, steps []op) {
+    t.Helper()
+    for _, s := range steps {
+        v, ok := names[s.from]
+        if !ok { t.Fatalf("step %s→%s: no source", s.from, s.to); return }
+        if _, taken := names[s.to]; taken && s.to != s.from { t.Fatalf("step %s→%s: destination taken", s.from, s.to); return }
+        delete(names, s.from)
+        names[s.to] = v
+    }
+}
+
+func TestOrderRenames(t *testing.T) {
+    tests := []struct {
+        name  string
+        ops   []op
+        steps int // including temporary moves
+    }{
+        {"independent", []op{{"/d/a", "/d/x"}, {"/d/b", "/d/y"}}, 2},
+        {"chain", []op{{"/d/a", "/d/b"}, {"/d/b", "/d/c"}, {"/d/c", "/d/d"}}, 3},
+        {"swap", []op{{"/d/a", "/d/b"}, {"/d/b", "/d/a"}}, 3},
+        {"cycle of three", []op{{"/d/a", "/d/b"}, {"/d/b", "/d/c"}, {"/d/c", "/d/a"}}, 4},
+        {"two swaps", []op{{"/d/a", "/d/b"}, {"/d/b", "/d/a"}, {"/d/c", "/d/e"}, {"/d/e", "/d/c"}}, 6},
+        {"chain into a cycle", []op{{"/d/x", "/d/a"}, {"/d/a", "/d/b"}, {"/d/b", "/d/a2"}, {"/d/a2", "/d/a3"}}, 4},
+        {"unchanged", []op{{"/d/a", "/d/a"}}, 1},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            names, want := map[string]string{}, map[string]string{}
+            for _, o := range tt.ops { names[o.from] = o.from }
+            for _, o := range tt.ops { want[o.to] = o.from }
+            steps := orderRenames(tt.ops)
+            if len(steps) != tt.steps { t.Errorf("%d steps, want %d: %v", len(steps), tt.steps, steps) }
+            simulate(t, names, steps)
+            if len(names) != len(want) { t.Fatalf("ended with %v, want %v", names, want) }
+            for to, from := range want {
+                if names[to] != from { t.Errorf("%s holds %q, want %q", to, names[to], from) }
+            }
+        })
+    }
+}
+
+// renameFixture creates files named after their content.
+func renameFixture(t *testing.T, names ...string) (string, func(string) string) {
+    t.Helper()
+    dir := t.TempDir()
+    p := func(s string) string { return filepath.Join(dir, s) }
+    for _, n := range names {
+        if err := os.WriteFile(p(n), []byte(n), 0o644); err != nil { t.Fatal(err) }
+    }
+    return dir, p
+}
+
+// contents maps every name in dir to its content.
+func contents(t *testing.T, dir string) map[string]string {
+    t.Helper()
+    des, err := os.ReadDir(dir)
+    if err != nil { t.Fatal(err) }
+    out := map[string]string{}
+    for _, d := range des {
+        b, _ := os.ReadFile(filepath.Join(dir, d.Name()))
+        out[d.Name()] = string(b)
+    }
+    return out
+}
+
+func wantContents(t *testing.T, dir string, want map[string]string) {
+    t.Helper()
+    got := contents(t, dir)
+    if len(got) != len(want) { t.Fatalf("dir holds %v, want %v", got, want) }
+    for n, c := range want {
+        if got[n] != c { t.Errorf("%s = %q, want %q (dir: %v)", n, got[n], c, got) }
+    }
+}
+
+func TestRenameJobRollback(t *testing.T) {
+    dir, p := renameFixture(t, "d", "e", "x")
+    // x→y, e→f and d→e run first; the source of the last step is missing
+    ops := []op{{p("x"), p("y")}, {p("d"), p("e")}, {p("e"), p("f")}, {p("ghost"), p("h")}}
+    j := &job{}
+    msg, err := renameJob(actRenamePattern, 1, ops)(context.Background(), j)
+    if err == nil { t.Fatal("plan succeeded") }
+    if !strings.Contains(err.Error(), "nothing was changed") { t.Errorf("err = %v", err) }
+    if j.n.Load() != 3 { t.Errorf("%d steps ran before the failure, want 3", j.n.Load()) }
+    if recs := msg.(batchDoneMsg).recs; len(recs) != 0 { t.Errorf("journalled %v after a rollback", recs) }
+    wantContents(t, dir, map[string]string{"d": "d", "e": "e", "x": "x"})
+}
+
+func TestRenameJobRefusesOverwrite(t *testing.T) {
+    dir, p := renameFixture(t, "a", "b", "c", "taken")
+    ops := []op{{p("a"), p("b")}, {p("b"), p("c")}, {p("c"), p("taken")}}
+    if _, err := renameJob(actRenamePattern, 1, ops)(context.Background(), &job{}); err == nil { t.Fatal("plan overwrote an entry") }
+    wantContents(t, dir, map[string]string{"a": "a", "b": "b", "c": "c", "taken": "taken"})
+}
+
+func TestRenameJobCancelled(t *testing.T) {
+    dir, p := renameFixture(t, "a", "b")
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    if _, err := renameJob(actRenamePattern, 1, []op{{p("a"), p("b")}, {p("b"), p("a")}})(ctx, &job{}); err == nil { t.Fatal("cancelled plan succeeded") }
+    wantContents(t, dir, map[string]string{"a": "a", "b": "b"})
+}
+
+// TestRenameJobUndo runs a plan with a cycle and a chain, then reverts the
+// journalled steps newest first, as undo does.
+func TestRenameJobUndo(t *testing.T) {
+    dir, p := renameFixture(t, "a", "b", "c", "x")
+    ops := []op{{p("a"), p("b")}, {p("b"), p("c")}, {p("c"), p("a")}, {p("x"), p("y")}}
+    msg, err := renameJob(actRenamePattern, 1, ops)(context.Background(), &job{})
+    if err != nil { t.Fatal(err) }
+    wantContents(t, dir, map[string]string{"a": "c", "b": "a", "c": "b", "y": "x"})
+    recs := msg.(batchDoneMsg).recs
+    if len(recs) != 5 { t.Errorf("journalled %d steps, want 5 (one through a temporary name)", len(recs)) }
+    for i := len(recs) - 1; i >= 0; i-- {
+        if err := recs[i].revert(context.Background()); err != nil { t.Fatalf("reverting %+v: %v", recs[i], err) }
+    }
+    wantContents(t, dir, map[string]string{"a": "a", "b": "b", "c": "c", "x": "x"})
+
+    // and redo, oldest first, gets the renamed state back
+    for _, r := range recs {
+        if _, err := r.apply(context.Background()); err != nil { t.Fatalf("reapplying %+v: %v", r, err) }
+    }
+    wantContents(t, dir, map[string]string{"a": "c", "b": "a", "c": "b", "y": "x"})
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "time"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Streaming directory scan ----------
+
+const (
+    scanBatch = 256                   // entries per ReadDir call
+    scanFlush = 80 * time.Millisecond // max delay before a partial chunk is sent
+)
+
+// scanChunkMsg delivers entries from a running scan. The last message has
+// done set; small directories arrive as a single done chunk.
+type scanChunkMsg struct {
+    id       int
+    items    []fileItem
+    seen     int // entries listed so far
+    readErrs int // failed reads: unreadable paths in a walk, failed batches in a listing
+    done     bool
+    err      error
+}
+
+// dirScan is one in-flight listing. Starting a new scan (navigating away,
+// refreshing) cancels the previous one; late chunks are dropped by id.
+type dirScan struct {
+    id     int
+    ch     chan scanChunkMsg
+    cancel context.CancelFunc
+}
+
+// startScan lists dir (browsing) or walks roots recursively (files only)
+// on a goroutine. sel restores selection marks across refreshes.
+func startScan(id int, browsing bool, dir string, roots []string, sel map[string]bool) *dirScan {
+    ctx, cancel := context.WithCancel(context.Background())
+    s := &dirScan{id: id, ch: make(chan scanChunkMsg, 2), cancel: cancel}
+    go func() {
+        defer close(s.ch)
+        st := scanChunkMsg{id: id}
+        last := time.Now()
+        emit := func(it fileItem) bool {
+            it.selected = sel[it.path]
+            st.items = append(st.items, it)
+            st.seen++
+            if time.Since(last) < scanFlush { return true }
+            last = time.Now()
+            out := st
+            st.items = nil
+            select {
+            case s.ch <- out: return true
+            case <-ctx.Done(): return false
+            }
+        }
+        if browsing {
+            st.err = readDirStream(ctx, dir, emit, &st.readErrs)
+        } else {
+            st.err = walkStream(ctx, roots, emit, &st.readErrs)
+        }
+        if ctx.Err() != nil { return }
+        st.done = true
+        select {
+        case s.ch <- st:
+        case <-ctx.Done():
+        }
+    }()
+    return s
+}
+
+// readDirStream reads dir in batches so huge or slow directories start
+// showing entries before the listing finishes. A batch that fails is
+// counted in readErrs and the listing carries on; it only gives up when
+// reads keep failing without returning anything.
+func readDirStream(ctx context.Context, dir string, emit func(fileItem) bool, readErrs *int) error {
+    f, err := os.Open(dir)
+    if err != nil { return err }
+    defer f.Close()
+    failures := 0
+    for {
+        if ctx.Err() != nil { return ctx.Err() }
+        entries, err := f.ReadDir(scanBatch)
+        for _, e := range entries {
+            if !emit(fileItem{path: filepath.Join(dir, e.Name()), isDir: e.IsDir()}) { return ctx.Err() }
+        }
+        if errors.Is(err, io.EOF) { return nil }
+        if err == nil { failures = 0; continue }
+        *readErrs++
+        if len(entries) > 0 { failures = 0 } else { failures++ }
+        if failures >= 3 { return err }
+    }
+}
+
+// walkStream walks roots without a cap; unreadable paths are counted in
+// readErrs instead of being dropped silently.
+func walkStream(ctx context.Context, roots []string, emit func(fileItem) bool, readErrs *int) error {
+    if len(roots) == 0 { roots = []string{"."} }
+    stop := errors.New("stop")
+    for _, p := range roots {
+        fi, err := os.Stat(p)
+        if err != nil { *readErrs++; continue }
+        if !fi.IsDir() {
+            if !emit(fileItem{path: p}) { return ctx.Err() }
+            continue
+        }
+        err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+            if ctx.Err() != nil { return stop }
+            if err != nil { *readErrs++; return nil }
+            if d.IsDir() { return nil }
+            if !emit(fileItem{path: path}) { return stop }
+            return nil
+        })
+        if err != nil { return ctx.Err() }
+    }
+    return nil
+}
+
+// waitScan reads the next chunk; Update re-arms it until the scan is done.
+func waitScan(s *dirScan) tea.Cmd {
+    ch := s.ch
+    return func() tea.Msg {
+        msg, ok := <-ch
+        if !ok { return nil }
+        return msg
+    }
+}
+
+// beginScan cancels any running scan and starts listing the current
+// directory (or the original arguments). focus is selected once it shows up.
+func (m *model) beginScan(focus string, sel map[string]bool) tea.Cmd {
+    m.cancelScan()
+    m.cancelStat()
+    if m.browsing {
+        sp, ok := m.sortPrefs.get(m.cwd)
+        if !ok { sp = sortSpec{Natural: m.sort.Natural} }
+        m.sort = sp
+    }
+    m.files.sortInfo = m.sort.String()
+    m.scanSeq++
+    m.scanFocus = focus
+    m.scanSeen, m.scanErrs = 0, 0
+    m.scanning = true
+    m.files.reset()
+    if m.treeMode { m.files.tree = newTreeState(m.treeReopen, sel) }
+    m.treeReopen = nil
+    m.scan = startScan(m.scanSeq, m.browsing, m.cwd, m.originalArgs, sel)
+    return waitScan(m.scan)
+}
+
+func (m *model) cancelScan() {
+    if m.scan == nil { return }
+    m.scan.cancel()
+    m.scan = nil
+    m.scanning = false
+}
+
+// applyScan appends a chunk and keeps the cursor on the focused path. The
+// preview follows only when the selected item actually changed.
+func (m *model) applyScan(msg scanChunkMsg) tea.Cmd {
+    if m.scan == nil || msg.id != m.scan.id { return nil }
+    before := ""
+    if it, ok := m.files.selectedItem(); ok { before = it.path }
+    m.files.append(msg.items)
+    m.scanSeen, m.scanErrs = msg.seen, msg.readErrs
+    if msg.done {
+        m.scan = nil
+        m.scanning = false
+        if msg.err != nil { m.status = "scan: " + msg.err.Error() }
+    }
+    if m.scanFocus != "" && m.selectPath(m.scanFocus) { m.scanFocus = "" }
+    if msg.done { m.scanFocus = "" }
+    var cmds []tea.Cmd
+    if !msg.done { cmds = append(cmds, waitScan(m.scan)) } else { cmds = append(cmds, m.applySort(), m.loadGitStatus()) }
+    if msg.done && m.files.tree != nil {
+        cmds = append(cmds, m.treeAutoExpand(m.files.tree.roots))
+        m.files.refreshTree()
+    }
+    after := ""
+    if it, ok := m.files.selectedItem(); ok { after = it.path }
+    if after != before || (msg.done && after == "") {
+        if after == "" {
+            m.cancelPreview()
+            m.lastDoc, m.lastPreviewRaw = nil, ""
+            m.sections = noSections()
+            m.preview.SetContent("(no entries)")
+        } else {
+            cmds = append(cmds, m.loadPreview())
+            m.prefetchScreen()
+        }
+    }
+    return tea.Batch(cmds...)
+}
+
+// selectPath moves the cursor to p if it is listed.
+func (m *model) selectPath(p string) bool {
+    pos := m.files.find(p)
+    if pos < 0 { return false }
+    m.files.selectPos(pos)
+    return true
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "runtime"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    tea "github.com/charmbracelet/bubbletea"
+
+    "github.com/NDeeSeee/finfo/tui/internal/inspect"
+)
+
+// ---------- Sorting ----------
+
+type sortKey int
+
+const (
+    sortName sortKey = iota
+    sortSize
+    sortModified
+    sortCreated
+    sortAccessed
+    sortExt
+    sortType
+    sortOwner
+    sortPerms
+    numSortKeys
+)
+
+var sortKeyNames = [numSortKeys]string{"name", "size", "modified", "created", "accessed", "ext", "type", "owner", "perms"}
+
+// sortKeyLetters are the keys of the sort menu (`s` then a letter).
+var sortKeyLetters = [numSortKeys]string{"n", "s", "m", "c", "a", "e", "t", "o", "p"}
+
+func parseSortKey(s string) (sortKey, bool) {
+    for k, n := range sortKeyNames { if n == s { return sortKey(k), true } }
+    return sortName, false
+}
+
+// needsStat reports whether the key compares stat data rather than names.
+func (k sortKey) needsStat() bool { return k != sortName && k != sortExt }
+
+// sortSpec is the active ordering. Directories always come first; Desc
+// reverses the order within each group.
+type sortSpec struct {
+    Key     sortKey
+    Desc    bool
+    Natural bool // compare digit runs numerically: file2 < file10
+}
+
+func (sp sortSpec) String() string {
+    s := sortKeyNames[sp.Key]
+    if sp.Desc { s += " ↓" } else { s += " ↑" }
+    if sp.Natural { s += " nat" }
+    return s
+}
+
+// entryMeta is the stat data sorting and the detail view need, packed per
+// entry.
+type entryMeta struct {
+    size  int64
+    mtime int64
+    btime int64 // birth time, or change time where birth time is unknown
+    atime int64
+    mode  uint32 // permission and special bits (07777)
+    uid   uint32
+    gid   uint32
+    nlink uint32
+    typ   byte // ls type character: d l - p s c b
+}
+
+func metaOf(fi fs.FileInfo) entryMeta {
+    st := inspect.StatOf(fi)
+    created := st.BTime
+    if created.IsZero() { created = st.CTime }
+    m := entryMeta{size: fi.Size(), mtime: st.MTime.UnixNano(), btime: created.UnixNano(), atime: st.ATime.UnixNano(), mode: st.Mode, uid: st.UID, gid: st.GID, nlink: uint32(st.Nlink), typ: '-'}
+    switch t := fi.Mode().Type(); {
+    case t&fs.ModeDir != 0: m.typ = 'd'
+    case t&fs.ModeSymlink != 0: m.typ = 'l'
+    case t&fs.ModeNamedPipe != 0: m.typ = 'p'
+    case t&fs.ModeSocket != 0: m.typ = 's'
+    case t&fs.ModeCharDevice != 0: m.typ = 'c'
+    case t&fs.ModeDevice != 0: m.typ = 'b'
+    case t != 0: m.typ = '?'
+    }
+    if st.ATime.IsZero() { m.atime = 0 }
+    if created.IsZero() { m.btime = 0 }
+    return m
+}
+
+// typeRank orders the type key: directories, symlinks, regular files, rest.
+func typeRank(c byte) int {
+    switch c {
+    case 'd': return 0
+    case 'l': return 1
+    case '-': return 2
+    }
+    return 3
+}
+
+func cmpInt64(a, b int64) int {
+    switch {
+    case a < b: return -1
+    case a > b: return 1
+    }
+    return 0
+}
+
+// extOf returns the extension without the dot; dotfiles have none.
+func extOf(name []byte) []byte {
+    for i := len(name) - 1; i > 0; i-- {
+        if name[i] == '.' { return name[i+1:] }
+    }
+    return nil
+}
+
+// natCmp compares names case-insensitively with digit runs compared by
+// value, so versions and numbered files sort the way people expect.
+func natCmp(a, b []byte) int {
+    for len(a) > 0 && len(b) > 0 {
+        if isDigit(a[0]) && isDigit(b[0]) {
+            i, j := digitRun(a), digitRun(b)
+            da, db := trimZeros(a[:i]), trimZeros(b[:j])
+            if len(da) != len(db) { return len(da) - len(db) }
+            if c := strings.Compare(string(da), string(db)); c != 0 { return c }
+            if i != j { return i - j } // fewer leading zeros first
+            a, b = a[i:], b[j:]
+            continue
+        }
+        i, j := textRun(a), textRun(b)
+        if c := foldCmp(a[:i], b[:j]); c != 0 { return c }
+        a, b = a[i:], b[j:]
+    }
+    return len(a) - len(b)
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func digitRun(s []byte) int { i := 0; for i < len(s) && isDigit(s[i]) { i++ }; return i }
+
+func textRun(s []byte) int { i := 0; for i < len(s) && !isDigit(s[i]) { i++ }; return i }
+
+func trimZeros(s []byte) []byte { for len(s) > 1 && s[0] == '0' { s = s[1:] }; return s }
+
+// less builds the comparator over store indices. byPath compares whole
+// paths instead of names (recursive listings of the arguments).
+func (sp sortSpec) less(s *fileStore, byPath bool) func(a, b int32) bool {
+    name := s.nameBytes
+    if byPath { name = s.pathBytes }
+    cmpName := foldCmp
+    if sp.Natural { cmpName = natCmp }
+    var owner []string
+    if sp.Key == sortOwner {
+        owner = make([]string, len(s.meta))
+        for i := range s.meta { owner[i], _ = inspect.OwnerNames(s.meta[i].uid, 0) }
+    }
+    meta := func(i int32) entryMeta {
+        if int(i) < len(s.meta) { return s.meta[i] }
+        return entryMeta{}
+    }
+    return func(a, b int32) bool {
+        da, db := s.flags[a]&entryDir != 0, s.flags[b]&entryDir != 0
+        if da != db { return da }
+        c := 0
+        switch sp.Key {
+        case sortSize: c = cmpInt64(meta(a).size, meta(b).size)
+        case sortModified: c = cmpInt64(meta(a).mtime, meta(b).mtime)
+        case sortCreated: c = cmpInt64(meta(a).btime, meta(b).btime)
+        case sortAccessed: c = cmpInt64(meta(a).atime, meta(b).atime)
+        case sortExt: c = foldCmp(extOf(s.nameBytes(int(a))), extOf(s.nameBytes(int(b))))
+        case sortType:
+            c = typeRank(meta(a).typ) - typeRank(meta(b).typ)
+            if c == 0 { c = foldCmp(extOf(s.nameBytes(int(a))), extOf(s.nameBytes(int(b)))) }
+        case sortOwner:
+            if int(a) < len(owner) && int(b) < len(owner) { c = strings.Compare(owner[a], owner[b]) }
+        case sortPerms: c = int(meta(a).mode) - int(meta(b).mode)
+        }
+        if c == 0 { c = cmpName(name(int(a)), name(int(b))) }
+        if sp.Desc { c = -c }
+        return c < 0
+    }
+}
+
+// ---------- Stat pass ----------
+
+// statMsg carries stat data for the store indices idx of the listing
+// identified by gen (the scan sequence number). full marks the pass that
+// covers the whole listing for sorting.
+type statMsg struct {
+    gen   int
+    idx   []int32
+    metas []entryMeta
+    full  bool
+}
+
+// statEntries lstat's idx on a few goroutines. s is a snapshot of the store
+// header: the UI goroutine only appends past its length and the paths are
+// never rewritten, so reading them concurrently is safe.
+func statEntries(ctx context.Context, gen int, s fileStore, idx []int32, full bool) tea.Cmd {
+    return func() tea.Msg {
+        metas := make([]entryMeta, len(idx))
+        workers := runtime.NumCPU()
+        if workers > 8 { workers = 8 }
+        var wg sync.WaitGroup
+        step := (len(idx) + workers - 1) / workers
+        for lo := 0; lo < len(idx); lo += step {
+            hi := lo + step
+            if hi > len(idx) { hi = len(idx) }
+            wg.Add(1)
+            go func(lo, hi int) {
+                defer wg.Done()
+                for k := lo; k < hi; k++ {
+                    if ctx.Err() != nil { return }
+                    if fi, err := os.Lstat(s.path(int(idx[k]))); err == nil { metas[k] = metaOf(fi) } else { metas[k].typ = '?' }
+                }
+            }(lo, hi)
+        }
+        wg.Wait()
+        if ctx.Err() != nil { return nil }
+        return statMsg{gen: gen, idx: idx, metas: metas, full: full}
+    }
+}
+
+// applySort orders the listing by m.sort, first gathering stat data when
+// the key needs it (the list keeps its current order meanwhile).
+func (m *model) applySort() tea.Cmd {
+    m.files.sortInfo = m.sort.String()
+    if m.sort.Key.needsStat() {
+        if m.statCancel != nil { return nil } // a pass is running; its result re-sorts
+        if idx := m.files.store.missingMeta(); len(idx) > 0 {
+            ctx, cancel := context.WithCancel(context.Background())
+            m.statCancel = cancel
+            m.status = "sorting…"
+            return statEntries(ctx, m.scanSeq, m.files.store, idx, true)
+        }
+    }
+    before := ""
+    if it, ok := m.files.selectedItem(); ok { before = it.path }
+    moved := m.files.index() > 0
+    m.files.sortBy(m.sort.less(&m.files.store, !m.browsing))
+    // a cursor the user never moved stays on the first entry
+    if !moved { m.files.top() }
+    return m.previewIfMoved(before)
+}
+
+func (m *model) applyStat(msg statMsg) tea.Cmd {
+    if msg.gen != m.scanSeq { return nil }
+    for k, i := range msg.idx { m.files.store.setMeta(int(i), msg.metas[k]) }
+    if !msg.full { return nil }
+    m.statCancel = nil
+    if m.status == "sorting…" { m.status = "" }
+    return m.applySort()
+}
+
+func (m *model) cancelStat() {
+    if m.statCancel == nil { return }
+    m.statCancel()
+    m.statCancel = nil
+}
+
+// previewIfMoved reloads the preview when the selected entry changed.
+func (m *model) previewIfMoved(before string) tea.Cmd {
+    it, ok := m.files.selectedItem()
+    if !ok || it.path == before { return nil }
+    cmd := m.loadPreview()
+    m.prefetchScreen()
+    return cmd
+}
+
+// sortMenuKey handles the key after `s`: a key letter picks that key (again
+// toggles direction), r reverses, v toggles natural order.
+func (m *model) sortMenuKey(k string) tea.Cmd {
+    m.mode = modeList
+    sp := m.sort
+    switch k {
+    case "r": sp.Desc = !sp.Desc
+    case "v": sp.Natural = !sp.Natural
+    default:
+        key := numSortKeys
+        for i, l := range sortKeyLetters { if l == k { key = sortKey(i) } }
+        if key == numSortKeys { return nil }
+        if key == sp.Key {
+            sp.Desc = !sp.Desc
+        } else {
+            // sizes and times are most useful largest/newest first
+            sp.Key = key
+            sp.Desc = key == sortSize || key == sortModified || key == sortCreated || key == sortAccessed
+        }
+    }
+    m.sort = sp
+    if m.browsing { m.sortPrefs.set(m.cwd, sp) }
+    m.status = "sort: " + sp.String()
+    return m.applySort()
+}
+
+func sortMenu(sp sortSpec) string {
+    b := &strings.Builder{}
+    b.WriteString("Sort by (current: " + sp.String() + ")\n\n")
+    for k := sortKey(0); k < numSortKeys; k++ {
+        mark := "  "
+        if k == sp.Key { mark = "• " }
+        b.WriteString(mark + sortKeyLetters[k] + "  " + sortKeyNames[k] + "\n")
+    }
+    b.WriteString("\n  r  reverse\n  v  natural order (file2 < file10)\n\nesc to close")
+    return b.String()
+}
+
+// ---------- Per-directory preference ----------
+
+type sortPref struct {
+    Key     string `json:"key"`
+    Desc    bool   `json:"desc"`
+    Natural bool   `json:"natural"`
+    At      int64  `json:"at"`
+}
+
+// sortPrefs persists the sort chosen for each directory under the state
+// dir. The file is small and rewritten on change; the least recently set
+// entries are dropped past maxSortPrefs.
+type sortPrefs struct {
+    path string
+    dirs map[string]sortPref
+}
+
+const maxSortPrefs = 500
+
+func loadSortPrefs() *sortPrefs {
+    p := &sortPrefs{dirs: map[string]sortPref{}}
+    if dir := stateDir(); dir != "" { p.path = filepath.Join(dir, "sort.json") }
+    if p.path == "" { return p }
+    if b, err := os.ReadFile(p.path); err == nil { _ = json.Unmarshal(b, &p.dirs) }
+    return p
+}
+
+func absDir(dir string) string {
+    if a, err := filepath.Abs(dir); err == nil { return a }
+    return dir
+}
+
+func (p *sortPrefs) get(dir string) (sortSpec, bool) {
+    if p == nil { return sortSpec{}, false }
+    pr, ok := p.dirs[absDir(dir)]
+    if !ok { return sortSpec{}, false }
+    k, ok := parseSortKey(pr.Key)
+    if !ok { return sortSpec{}, false }
+    return sortSpec{Key: k, Desc: pr.Desc, Natural: pr.Natural}, true
+}
+
+func (p *sortPrefs) set(dir string, sp sortSpec) {
+    if p == nil { return }
+    p.dirs[absDir(dir)] = sortPref{Key: sortKeyNames[sp.Key], Desc: sp.Desc, Natural: sp.Natural, At: time.Now().Unix()}
+    if len(p.dirs) > maxSortPrefs {
+        keys := make([]string, 0, len(p.dirs))
+        for k := range p.dirs { keys = append(keys, k) }
+        sort.Slice(keys, func(i, j int) bool { return p.dirs[keys[i]].At < p.dirs[keys[j]].At })
+        for _, k := range keys[:len(keys)-maxSortPrefs] { delete(p.dirs, k) }
+    }
+    if p.path == "" { return }
+    b, err := json.MarshalIndent(p.dirs, "", "  ")
+    if err != nil { return }
+    if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil { return }
+    _ = writeFileAtomic(p.path, b)
+}
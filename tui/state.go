@@ -0,0 +1,32 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+)
+
+// ---------- Persistent state ----------
+
+// stateDir returns $XDG_STATE_HOME/finfo/tui (default ~/.local/state/finfo/tui)
+// for small files that should survive restarts but are not configuration.
+func stateDir() string {
+    base := os.Getenv("XDG_STATE_HOME")
+    if base == "" {
+        home, err := os.UserHomeDir()
+        if err != nil { return "" }
+        base = filepath.Join(home, ".local", "state")
+    }
+    return filepath.Join(base, "finfo", "tui")
+}
+
+// writeFileAtomic writes via a temp file + rename so readers never see a
+// torn file; the data is synced before the rename.
+func writeFileAtomic(dst string, b []byte) error {
+    tmp, err := os.CreateTemp(filepath.Dir(dst), ".tmp-*")
+    if err != nil { return err }
+    if _, err := tmp.Write(b); err != nil { tmp.Close(); os.Remove(tmp.Name()); return err }
+    if err := tmp.Sync(); err != nil { tmp.Close(); os.Remove(tmp.Name()); return err }
+    if err := tmp.Close(); err != nil { os.Remove(tmp.Name()); return err }
+    if err := os.Rename(tmp.Name(), dst); err != nil { os.Remove(tmp.Name()); return err }
+    return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+    "fmt"
+    "path/filepath"
+    "strconv"
+    "strings"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Tabs ----------
+
+// workspace is the per-tab browsing state. The active tab lives in the
+// model's own fields; tabs[m.tab] is only written when switching away.
+type workspace struct {
+    files    vlist // listing, selection marks, filter, columns
+    browsing bool
+    cwd      string
+    dirStack []string
+    sort     sortSpec
+    treeMode bool
+}
+
+// stopTab cancels the active tab's background work.
+func (m *model) stopTab() {
+    m.cancelScan()
+    m.cancelStat()
+    if m.files.tree != nil { m.files.tree.cancel() }
+}
+
+func (m *model) stash() {
+    m.stopTab()
+    m.tabs[m.tab] = workspace{files: m.files, browsing: m.browsing, cwd: m.cwd, dirStack: m.dirStack, sort: m.sort, treeMode: m.treeMode}
+}
+
+// activate makes tab i current and rescans its listing, keeping its cursor,
+// selection marks and expanded directories.
+func (m *model) activate(i int) tea.Cmd {
+    ws := m.tabs[i]
+    m.tab = i
+    m.files, m.browsing, m.cwd, m.dirStack, m.sort, m.treeMode = ws.files, ws.browsing, ws.cwd, ws.dirStack, ws.sort, ws.treeMode
+    m.applyLayout()
+    return m.reloadList()
+}
+
+func (m *model) switchTab(i int) tea.Cmd {
+    if i == m.tab || i < 0 || i >= len(m.tabs) { return nil }
+    m.stash()
+    return m.activate(i)
+}
+
+// newTab opens the current directory (or, with file arguments, the one
+// holding the cursor entry) in a new tab after the active one.
+func (m *model) newTab() tea.Cmd {
+    dir := m.cwd
+    if !m.browsing {
+        it, ok := m.files.selectedItem()
+        if !ok { return nil }
+        dir = filepath.Dir(it.path)
+    }
+    m.stash()
+    fl := newVList(m.previewStates)
+    fl.cols, fl.detail = m.files.cols, m.files.detail
+    ws := workspace{files: fl, browsing: true, cwd: dir, dirStack: append([]string(nil), m.dirStack...), sort: m.sort, treeMode: m.treeMode}
+    at := m.tab + 1
+    m.tabs = append(m.tabs[:at], append([]workspace{ws}, m.tabs[at:]...)...)
+    return m.activate(at)
+}
+
+func (m *model) closeTab() tea.Cmd {
+    if len(m.tabs) == 1 { m.status = "last tab"; return nil }
+    m.stopTab()
+    m.tabs = append(m.tabs[:m.tab], m.tabs[m.tab+1:]...)
+    next := m.tab
+    if next >= len(m.tabs) { next = len(m.tabs) - 1 }
+    return m.activate(next)
+}
+
+func (m *model) tabName(i int) string {
+    ws := m.tabs[i]
+    if i == m.tab { ws.browsing, ws.cwd = m.browsing, m.cwd }
+    if !ws.browsing { return "args" }
+    if abs, err := filepath.Abs(ws.cwd); err == nil { return filepath.Base(abs) }
+    return filepath.Base(ws.cwd)
+}
+
+// tabBar lists the tabs next to the title; it is empty with a single tab.
+func (m *model) tabBar() string {
+    if len(m.tabs) < 2 { return "" }
+    parts := make([]string, len(m.tabs))
+    for i := range m.tabs {
+        label := fmt.Sprintf(" %d:%s ", i+1, m.tabName(i))
+        if i == m.tab { parts[i] = m.files.titleStyle.Render(label) } else { parts[i] = m.theme.status.Render(label) }
+    }
+    return "  " + strings.Join(parts, " ")
+}
+
+// ---------- Sending selections between tabs ----------
+
+// sendMenuKey handles the send-to-tab overlay: c/m pick copy or move, a
+// tab number builds the plan shown in the ops preview.
+func (m *model) sendMenuKey(k string) tea.Cmd {
+    switch k {
+    case "esc", "q", ">":
+        m.mode = modeList
+        return nil
+    case "c": m.sendMove = false; return nil
+    case "m": m.sendMove = true; return nil
+    }
+    n, err := strconv.Atoi(k)
+    if err != nil || n < 1 || n > len(m.tabs) { return nil }
+    i := n - 1
+    if i == m.tab { m.status = "that is the current tab"; return nil }
+    if !m.tabs[i].browsing { m.status = "tab " + k + " has no directory"; return nil }
+    act := actCopyTo
+    if m.sendMove { act = actMoveToDir }
+    m.planTransfer(act, m.tabs[i].cwd)
+    return nil
+}
+
+func (m *model) sendMenu() string {
+    b := &strings.Builder{}
+    verb := "Copy"
+    if m.sendMove { verb = "Move" }
+    fmt.Fprintf(b, "%s %d item(s) to tab\n\n", verb, len(m.targetItems()))
+    for i := range m.tabs {
+        if i == m.tab { continue }
+        dir := m.tabs[i].cwd
+        if !m.tabs[i].browsing { dir = "(no directory)" }
+        fmt.Fprintf(b, "  %d  %s\n", i+1, dir)
+    }
+    b.WriteString("\n  c copy · m move · esc to close")
+    return b.String()
+}
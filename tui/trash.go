@@ -0,0 +1,331 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io/fs"
+    "net/url"
+    "os"
+    "path/filepath"
+    "runtime"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Trash (freedesktop.org spec) ----------
+
+// Trashing moves an entry into a trash directory's files/ and describes it
+// in info/<name>.trashinfo; it never deletes. The home trash is used for
+// entries on the home filesystem, $topdir/.Trash/$uid or $topdir/.Trash-$uid
+// for other mounts.
+
+const trashDateLayout = "2006-01-02T15:04:05"
+
+func homeTrash() string {
+    data := os.Getenv("XDG_DATA_HOME")
+    if data == "" {
+        home, err := os.UserHomeDir()
+        if err != nil { return "" }
+        data = filepath.Join(home, ".local", "share")
+    }
+    return filepath.Join(data, "Trash")
+}
+
+// mountTop returns the top directory of the filesystem holding p.
+func mountTop(p string) string {
+    fi, err := os.Lstat(p)
+    if err != nil { return "" }
+    dev := fileDev(fi)
+    top := p
+    for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+        di, err := os.Stat(dir)
+        if err != nil || fileDev(di) != dev { break }
+        top = dir
+        if filepath.Dir(dir) == dir { break }
+    }
+    return top
+}
+
+// topTrash returns the trash directory for entries under top, creating
+// .Trash-$uid when the shared .Trash/$uid cannot be used.
+func topTrash(top string) (string, error) {
+    uid := strconv.Itoa(os.Getuid())
+    shared := filepath.Join(top, ".Trash")
+    // the spec requires a sticky, non-symlink .Trash before using it
+    if fi, err := os.Lstat(shared); err == nil && fi.IsDir() && fi.Mode()&fs.ModeSticky != 0 {
+        dir := filepath.Join(shared, uid)
+        if err := os.MkdirAll(dir, 0o700); err == nil { return dir, nil }
+    }
+    dir := filepath.Join(top, ".Trash-"+uid)
+    if err := os.MkdirAll(dir, 0o700); err != nil { return "", err }
+    if fi, err := os.Lstat(dir); err != nil || !fi.IsDir() { return "", fmt.Errorf("%s: not a usable trash directory", dir) }
+    return dir, nil
+}
+
+// trashPut moves p into the trash and returns its new location. When no
+// trash on p's filesystem can be used, p is moved to the home trash, which
+// copies it across filesystems; p is never just deleted.
+func trashPut(ctx context.Context, p string) (string, error) {
+    p = absDir(p)
+    fi, err := os.Lstat(p)
+    if err != nil { return "", err }
+    home := homeTrash()
+    if home == "" { return "", errors.New("no home directory for the Trash; not deleting") }
+    if err := os.MkdirAll(home, 0o700); err != nil { return "", err }
+    dir, infoPath := home, p
+    if hi, err := os.Stat(home); err == nil && fileDev(hi) != fileDev(fi) {
+        top := mountTop(p)
+        if td, err := topTrash(top); err == nil {
+            dir = td
+            if rel, err := filepath.Rel(top, p); err == nil { infoPath = rel }
+        }
+    }
+    for _, sub := range []string{"files", "info"} {
+        if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil { return "", err }
+    }
+    // the info file is created exclusively first: it reserves the name,
+    // unless files/ already holds an entry under it (orphaned, or put there
+    // by another tool) that the move would replace
+    base := filepath.Base(p)
+    ext := filepath.Ext(base)
+    name := base
+    var info *os.File
+    for n := 2; ; n++ {
+        info, err = os.OpenFile(filepath.Join(dir, "info", name+".trashinfo"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+        if err == nil {
+            _, serr := os.Lstat(filepath.Join(dir, "files", name))
+            if os.IsNotExist(serr) { break }
+            info.Close()
+            os.Remove(info.Name())
+            if serr != nil { return "", serr }
+        } else if !os.IsExist(err) {
+            return "", err
+        }
+        name = fmt.Sprintf("%s.%d%s", strings.TrimSuffix(base, ext), n, ext)
+    }
+    infoFile := info.Name()
+    _, err = fmt.Fprintf(info, "[Trash Info]\nPath=%s\nDeletionDate=%s\n", (&url.URL{Path: infoPath}).EscapedPath(), time.Now().Format(trashDateLayout))
+    if cerr := info.Close(); err == nil { err = cerr }
+    if err != nil { os.Remove(infoFile); return "", err }
+    loc := filepath.Join(dir, "files", name)
+    if _, err := moveEntry(ctx, p, loc); err != nil { os.Remove(infoFile); return "", err }
+    return loc, nil
+}
+
+// trashDirs lists the trash directories to browse: the home trash and those
+// at the top of mounted filesystems (or ~/.Trash on macOS).
+func trashDirs() []string {
+    var dirs []string
+    if h := homeTrash(); h != "" { dirs = append(dirs, h) }
+    if runtime.GOOS == "darwin" {
+        if home, err := os.UserHomeDir(); err == nil { dirs = append(dirs, filepath.Join(home, ".Trash")) }
+        return dirs
+    }
+    b, err := os.ReadFile("/proc/self/mounts")
+    if err != nil { return dirs }
+    uid := strconv.Itoa(os.Getuid())
+    for _, line := range strings.Split(string(b), "\n") {
+        f := strings.Fields(line)
+        if len(f) < 2 { continue }
+        top := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\134`, `\`).Replace(f[1])
+        for _, dir := range []string{filepath.Join(top, ".Trash", uid), filepath.Join(top, ".Trash-"+uid)} {
+            if fi, err := os.Stat(filepath.Join(dir, "files")); err == nil && fi.IsDir() { dirs = append(dirs, dir) }
+        }
+    }
+    return dirs
+}
+
+// trashInfo reads the original path and deletion date from a .trashinfo
+// file; relative paths are relative to top.
+func trashInfo(file, top string) (orig, date string) {
+    f, err := os.Open(file)
+    if err != nil { return "", "" }
+    defer f.Close()
+    sc := bufio.NewScanner(f)
+    for sc.Scan() {
+        k, v, ok := strings.Cut(sc.Text(), "=")
+        if !ok { continue }
+        switch k {
+        case "Path":
+            if u, err := url.PathUnescape(v); err == nil { orig = u } else { orig = v }
+            if !filepath.IsAbs(orig) { orig = filepath.Join(top, orig) }
+        case "DeletionDate":
+            date = v
+        }
+    }
+    return orig, date
+}
+
+// trashTop is the directory relative info paths in dir start from.
+func trashTop(dir string) string {
+    if strings.HasPrefix(filepath.Base(dir), ".Trash-") { return filepath.Dir(dir) }
+    return filepath.Dir(filepath.Dir(dir)) // $topdir/.Trash/$uid
+}
+
+// infoFile is the .trashinfo file for an entry in a trash's files/.
+func infoFile(loc string) string {
+    return filepath.Join(filepath.Dir(filepath.Dir(loc)), "info", filepath.Base(loc)+".trashinfo")
+}
+
+// restoreFromTrash moves a trashed entry back to orig, recreating its
+// directory, and drops its .trashinfo file when there is one.
+func restoreFromTrash(ctx context.Context, loc, orig string) error {
+    if _, err := os.Lstat(orig); err == nil { return fmt.Errorf("%s: exists again", orig) }
+    if err := os.MkdirAll(filepath.Dir(orig), 0o755); err != nil { return err }
+    if _, err := moveEntry(ctx, loc, orig); err != nil { return err }
+    if filepath.Base(filepath.Dir(loc)) == "files" { os.Remove(infoFile(loc)) }
+    return nil
+}
+
+// ---------- Trash browser ----------
+
+type trashItem struct {
+    loc   string // entry in a trash's files/ (or ~/.Trash)
+    orig  string // where it was trashed from; empty when unknown
+    date  string
+    isDir bool
+}
+
+type trashView struct {
+    items   []trashItem
+    cursor  int
+    confirm string // "purge" or "empty" while asking
+    err     error
+}
+
+type trashListMsg struct {
+    items []trashItem
+    err   error
+}
+
+func loadTrash() tea.Cmd {
+    return func() tea.Msg {
+        var msg trashListMsg
+        for _, dir := range trashDirs() {
+            files := filepath.Join(dir, "files")
+            if filepath.Base(dir) == ".Trash" && runtime.GOOS == "darwin" { files = dir }
+            entries, err := os.ReadDir(files)
+            if err != nil {
+                if !os.IsNotExist(err) { msg.err = err }
+                continue
+            }
+            for _, e := range entries {
+                it := trashItem{loc: filepath.Join(files, e.Name()), isDir: e.IsDir()}
+                if files != dir { it.orig, it.date = trashInfo(infoFile(it.loc), trashTop(dir)) }
+                msg.items = append(msg.items, it)
+            }
+        }
+        sort.Slice(msg.items, func(i, j int) bool { return msg.items[i].date > msg.items[j].date })
+        return msg
+    }
+}
+
+type trashDoneMsg struct {
+    verb string
+    n    int
+}
+
+// openTrash shows the browser and (re)loads its listing.
+func (m *model) openTrash() tea.Cmd {
+    m.trash = &trashView{}
+    m.mode = modeTrash
+    return loadTrash()
+}
+
+func (m *model) applyTrashList(msg trashListMsg) {
+    if m.trash == nil { return }
+    m.trash.items, m.trash.err = msg.items, msg.err
+    if m.trash.cursor >= len(msg.items) { m.trash.cursor = len(msg.items) - 1 }
+    if m.trash.cursor < 0 { m.trash.cursor = 0 }
+}
+
+// trashKey drives the browser: r restores the entry under the cursor to its
+// original place ("name (n)" when that is taken), d purges it and E empties
+// the trash, both after a y/N confirmation.
+func (m *model) trashKey(k string) tea.Cmd {
+    t := m.trash
+    if t.confirm != "" {
+        verb := t.confirm
+        t.confirm = ""
+        if k != "y" && k != "Y" { m.status = "cancelled"; return nil }
+        items := t.items
+        if verb == "purge" {
+            if t.cursor >= len(items) { return nil }
+            items = items[t.cursor : t.cursor+1]
+        }
+        act := "purge"
+        if verb == "empty" { act = "empty trash" }
+        return m.startJob(act, fmt.Sprintf("%d item(s)", len(items)), func(ctx context.Context, j *job) (tea.Msg, error) {
+            msg := trashDoneMsg{verb: "purged"}
+            j.total.Store(int64(len(items)))
+            for _, it := range items {
+                if err := ctx.Err(); err != nil { return msg, err }
+                if err := os.RemoveAll(it.loc); err != nil { return msg, err }
+                if it.orig != "" { os.Remove(infoFile(it.loc)) }
+                msg.n++
+                j.n.Add(1)
+            }
+            return msg, nil
+        })
+    }
+    switch k {
+    case "esc", "q", "X": m.mode = modeList; m.trash = nil
+    case "up", "k": if t.cursor > 0 { t.cursor-- }
+    case "down", "j": if t.cursor < len(t.items)-1 { t.cursor++ }
+    case "d": if len(t.items) > 0 { t.confirm = "purge" }
+    case "E": if len(t.items) > 0 { t.confirm = "empty" }
+    case "r", "enter":
+        if t.cursor >= len(t.items) { return nil }
+        it := t.items[t.cursor]
+        if it.orig == "" { m.status = "original location unknown"; return nil }
+        to := it.orig
+        if _, err := os.Lstat(to); err == nil { to = uniqueDest(filepath.Dir(to), filepath.Base(to)) }
+        return m.startJob("restore", filepath.Base(to), func(ctx context.Context, _ *job) (tea.Msg, error) {
+            if err := restoreFromTrash(ctx, it.loc, to); err != nil { return trashDoneMsg{verb: "restored"}, err }
+            return trashDoneMsg{verb: "restored", n: 1}, nil
+        })
+    }
+    return nil
+}
+
+func (m *model) applyTrashDone(msg trashDoneMsg) tea.Cmd {
+    m.status = fmt.Sprintf("%s %d item(s)", msg.verb, msg.n)
+    cmds := []tea.Cmd{m.reloadList(), m.refreshPeer()}
+    if m.trash != nil { cmds = append(cmds, loadTrash()) }
+    return tea.Batch(cmds...)
+}
+
+// trashMenu renders the browser around the cursor, newest first.
+func (m *model) trashMenu() string {
+    t := m.trash
+    b := &strings.Builder{}
+    fmt.Fprintf(b, "Trash (%d item(s))\n\n", len(t.items))
+    if t.err != nil { fmt.Fprintf(b, "  %s\n\n", t.err) }
+    rows := m.height - 12
+    if rows < 5 { rows = 5 }
+    start := 0
+    if t.cursor >= rows { start = t.cursor - rows + 1 }
+    for k := start; k < len(t.items) && k < start+rows; k++ {
+        it := t.items[k]
+        mark := "  "
+        if k == t.cursor { mark = "› " }
+        name := filepath.Base(it.loc)
+        if it.isDir { name += "/" }
+        from := it.orig
+        if from == "" { from = "(original location unknown)" }
+        date := strings.Replace(it.date, "T", " ", 1)
+        fmt.Fprintf(b, "%s%-30s %-19s %s\n", mark, name, date, from)
+    }
+    switch t.confirm {
+    case "purge": b.WriteString("\n  permanently delete this item? y/N")
+    case "empty": fmt.Fprintf(b, "\n  permanently delete all %d item(s)? y/N", len(t.items))
+    default: b.WriteString("\n  r restore · d delete permanently · E empty trash · esc to close")
+    }
+    return b.String()
+}
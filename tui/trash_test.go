@@ -0,0 +1,45 @@
+package main
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// TestTrashPutKeepsOrphans trashes entries whose names are already taken in
+// files/ without a matching .trashinfo; the old entries must survive.
+func TestTrashPutKeepsOrphans(t *testing.T) {
+    data, work := t.TempDir(), t.TempDir()
+    t.Setenv("XDG_DATA_HOME", data)
+    files := filepath.Join(data, "Trash", "files")
+    os.MkdirAll(filepath.Join(files, "d", "inner"), 0o700)
+    os.WriteFile(filepath.Join(files, "f.txt"), []byte("orphan"), 0o600)
+    os.WriteFile(filepath.Join(files, "d", "inner", "old"), []byte("orphan"), 0o600)
+
+    tests := []struct {
+        name  string
+        setup func(p string)
+        want  string // new name in files/
+    }{
+        {"f.txt", func(p string) { os.WriteFile(p, []byte("new"), 0o644) }, "f.2.txt"},
+        {"d", func(p string) { os.MkdirAll(filepath.Join(p, "inner"), 0o755); os.WriteFile(filepath.Join(p, "inner", "new"), []byte("new"), 0o644) }, "d.2"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            p := filepath.Join(work, tt.name)
+            tt.setup(p)
+            loc, err := trashPut(context.Background(), p)
+            if err != nil { t.Fatal(err) }
+            if filepath.Base(loc) != tt.want { t.Errorf("trashed to %s, want %s", filepath.Base(loc), tt.want) }
+            if _, err := os.Lstat(p); !os.IsNotExist(err) { t.Errorf("%s still in place", p) }
+            info, err := os.ReadFile(filepath.Join(data, "Trash", "info", tt.want+".trashinfo"))
+            if err != nil || !strings.Contains(string(info), "Path="+p) { t.Errorf("trashinfo = %q, %v", info, err) }
+            if _, err := os.Stat(filepath.Join(data, "Trash", "info", tt.name+".trashinfo")); !os.IsNotExist(err) { t.Errorf("stray trashinfo for the orphan's name") }
+        })
+    }
+    if b, _ := os.ReadFile(filepath.Join(files, "f.txt")); string(b) != "orphan" { t.Errorf("orphaned file replaced: %q", b) }
+    if _, err := os.Stat(filepath.Join(files, "d", "inner", "old")); err != nil { t.Errorf("orphaned directory changed: %v", err) }
+    if _, err := os.Stat(filepath.Join(files, "d", "inner", "new")); !os.IsNotExist(err) { t.Errorf("new directory merged into the orphan") }
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "strings"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Tree view ----------
+
+const (
+    treeMaxDepth     = 16    // expand-all does not go deeper
+    treeExpandAllMax = 50000 // entries loaded before expand-all stops
+    treeWorkers      = 4     // concurrent directory reads
+    treeUsageWorkers = 2     // concurrent usage walks, apart so they never hold up expanding
+)
+
+// dirUsage aggregates a directory subtree: regular files and their bytes.
+type dirUsage struct {
+    files int64
+    bytes int64
+    done  bool
+}
+
+// treeState is the tree view's shape over the flat fileStore. Children are
+// read lazily when a directory is first expanded and stay loaded (hidden)
+// when it collapses; the display order is rebuilt from roots and kids.
+type treeState struct {
+    roots     []int32
+    kids      map[int32][]int32
+    parent    map[int32]int32
+    loading   map[int32]bool
+    usage     map[int32]dirUsage // absent: not asked, !done: walking
+    expandAll bool
+    reopen    map[string]bool // directories to re-expand after a refresh
+    sel       map[string]bool // selection marks to restore in loaded children
+    ctx       context.Context
+    cancel    context.CancelFunc
+    sem       chan struct{} // child-list loads
+    usageSem  chan struct{} // usage walks
+}
+
+func newTreeState(reopen, sel map[string]bool) *treeState {
+    ctx, cancel := context.WithCancel(context.Background())
+    if reopen == nil { reopen = map[string]bool{} }
+    return &treeState{kids: map[int32][]int32{}, parent: map[int32]int32{}, loading: map[int32]bool{}, usage: map[int32]dirUsage{}, reopen: reopen, sel: sel, ctx: ctx, cancel: cancel, sem: make(chan struct{}, treeWorkers), usageSem: make(chan struct{}, treeUsageWorkers)}
+}
+
+// walk visits entries depth-first in display order, descending into
+// expanded directories only, or into every loaded one when openOnly is false.
+func (t *treeState) walk(s *fileStore, fn func(i int32), openOnly bool) {
+    var rec func(ids []int32)
+    rec = func(ids []int32) {
+        for _, i := range ids {
+            fn(i)
+            if openOnly && s.flags[i]&entryOpen == 0 { continue }
+            if kids, ok := t.kids[i]; ok { rec(kids) }
+        }
+    }
+    rec(t.roots)
+}
+
+// rebuildTree recomputes the display order from the expanded directories,
+// keeping the cursor on keep and the window where it was.
+func (l *vlist) rebuildTree(keep int32) {
+    l.order = l.order[:0]
+    l.tree.walk(&l.store, func(i int32) { l.order = append(l.order, i) }, true)
+    off := l.offset
+    l.rebuildView(keep)
+    l.offset = off
+    l.scroll()
+}
+
+func (l *vlist) refreshTree() {
+    keep := int32(-1)
+    if l.cursor < len(l.view) { keep = l.view[l.cursor] }
+    l.rebuildTree(keep)
+}
+
+// addKids stores the children of parent one level below it.
+func (l *vlist) addKids(parent int32, items []fileItem) []int32 {
+    ids := make([]int32, len(items))
+    for k, it := range items {
+        i := int32(l.store.len())
+        it.selected = l.tree.sel[it.path]
+        l.store.add(it)
+        if it.selected { l.nsel++ }
+        l.tree.parent[i] = parent
+        ids[k] = i
+    }
+    l.tree.kids[parent] = ids
+    return ids
+}
+
+// openPaths lists expanded directories so a refresh can restore them.
+func (l *vlist) openPaths() map[string]bool {
+    if l.tree == nil { return nil }
+    open := map[string]bool{}
+    l.tree.walk(&l.store, func(i int32) { if l.store.flags[i]&entryOpen != 0 { open[l.store.path(int(i))] = true } }, true)
+    return open
+}
+
+// treeLabel is the tree view's name cell: indentation, an expander and,
+// with usage, the aggregated size of a directory subtree once it is known.
+func (l *vlist) treeLabel(i int, usage bool) string {
+    var b strings.Builder
+    b.WriteString(strings.Repeat("  ", int(l.store.depth[i])))
+    name := string(l.store.nameBytes(i))
+    if l.store.flags[i]&entryDir == 0 {
+        b.WriteString("  " + name)
+        return b.String()
+    }
+    if l.store.flags[i]&entryOpen != 0 { b.WriteString("▾ ") } else { b.WriteString("▸ ") }
+    b.WriteString(name + "/")
+    if l.tree.loading[int32(i)] { b.WriteString(" (loading…)") }
+    if u, ok := l.tree.usage[int32(i)]; ok && usage {
+        if !u.done {
+            b.WriteString("  …")
+        } else if u.files == 1 {
+            b.WriteString("  1 file · " + sizeFmt(u.bytes, unitScheme()))
+        } else {
+            fmt.Fprintf(&b, "  %d files · %s", u.files, sizeFmt(u.bytes, unitScheme()))
+        }
+    }
+    return b.String()
+}
+
+// ---------- Loading ----------
+
+type treeKidsMsg struct {
+    gen    int
+    parent int32
+    items  []fileItem
+    err    error
+}
+
+type treeUsageMsg struct {
+    gen int
+    idx int32
+    u   dirUsage
+}
+
+func loadTreeKids(t *treeState, gen int, parent int32, dir string, depth int) tea.Cmd {
+    ctx, sem := t.ctx, t.sem
+    return func() tea.Msg {
+        select {
+        case sem <- struct{}{}:
+        case <-ctx.Done(): return nil
+        }
+        defer func() { <-sem }()
+        entries, err := os.ReadDir(dir)
+        items := make([]fileItem, 0, len(entries))
+        for _, e := range entries {
+            items = append(items, fileItem{path: filepath.Join(dir, e.Name()), isDir: e.IsDir(), depth: depth})
+        }
+        return treeKidsMsg{gen: gen, parent: parent, items: items, err: err}
+    }
+}
+
+// dirUsageCmd walks dir to total its files and bytes; symlinks are counted
+// but not followed.
+func dirUsageCmd(t *treeState, gen int, idx int32, dir string) tea.Cmd {
+    ctx, sem := t.ctx, t.usageSem
+    return func() tea.Msg {
+        select {
+        case sem <- struct{}{}:
+        case <-ctx.Done(): return nil
+        }
+        defer func() { <-sem }()
+        var u dirUsage
+        filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+            if ctx.Err() != nil { return ctx.Err() }
+            if err != nil || d.IsDir() { return nil }
+            u.files++
+            if fi, err := d.Info(); err == nil { u.bytes += fi.Size() }
+            return nil
+        })
+        if ctx.Err() != nil { return nil }
+        u.done = true
+        return treeUsageMsg{gen: gen, idx: idx, u: u}
+    }
+}
+
+// ---------- Model glue ----------
+
+// toggleTree switches between the flat listing and the tree view by
+// rescanning the current directory.
+func (m *model) toggleTree() tea.Cmd {
+    if !m.browsing { m.status = "tree view needs a directory argument"; return nil }
+    m.treeMode = !m.treeMode
+    return m.reloadList()
+}
+
+// treeExpand marks directory i open and loads its children if needed; the
+// caller rebuilds the display order.
+func (m *model) treeExpand(i int32) tea.Cmd {
+    t, s := m.files.tree, &m.files.store
+    if s.flags[i]&entryDir == 0 { return nil }
+    s.flags[i] |= entryOpen
+    if _, ok := t.kids[i]; ok { return m.treeAutoExpand(t.kids[i]) }
+    if t.loading[i] { return nil }
+    t.loading[i] = true
+    return loadTreeKids(t, m.scanSeq, i, s.path(int(i)), int(s.depth[i])+1)
+}
+
+// treeAutoExpand opens directories among ids that a refresh should restore
+// or that expand-all reaches.
+func (m *model) treeAutoExpand(ids []int32) tea.Cmd {
+    t, s := m.files.tree, &m.files.store
+    var cmds []tea.Cmd
+    for _, i := range ids {
+        if s.flags[i]&entryDir == 0 { continue }
+        p := s.path(int(i))
+        all := t.expandAll && int(s.depth[i]) < treeMaxDepth
+        if !all && !t.reopen[p] { continue }
+        if all && s.len() >= treeExpandAllMax {
+            t.expandAll = false
+            m.status = fmt.Sprintf("expand-all stopped at %d entries", s.len())
+            break
+        }
+        delete(t.reopen, p)
+        cmds = append(cmds, m.treeExpand(i))
+    }
+    return tea.Batch(cmds...)
+}
+
+func (m *model) applyTreeKids(msg treeKidsMsg) tea.Cmd {
+    t := m.files.tree
+    if t == nil || msg.gen != m.scanSeq { return nil }
+    delete(t.loading, msg.parent)
+    if msg.err != nil { m.status = "tree: " + msg.err.Error() }
+    ids := m.files.addKids(msg.parent, msg.items)
+    more := m.treeAutoExpand(ids)
+    m.files.refreshTree()
+    return tea.Batch(m.applySort(), more) // sorts the new siblings
+}
+
+func (m *model) applyTreeUsage(msg treeUsageMsg) {
+    if m.files.tree == nil || msg.gen != m.scanSeq { return }
+    m.files.tree.usage[msg.idx] = msg.u
+}
+
+// treeUsageVisible starts usage walks for directories in the window.
+func (m *model) treeUsageVisible() tea.Cmd {
+    t := m.files.tree
+    if t == nil { return nil }
+    end := m.files.offset + m.files.perPage()
+    if end > len(m.files.view) { end = len(m.files.view) }
+    var cmds []tea.Cmd
+    for pos := m.files.offset; pos < end; pos++ {
+        i := m.files.view[pos]
+        if m.files.store.flags[i]&entryDir == 0 { continue }
+        if _, ok := t.usage[i]; ok { continue }
+        t.usage[i] = dirUsage{}
+        cmds = append(cmds, dirUsageCmd(t, m.scanSeq, i, m.files.store.path(int(i))))
+    }
+    return tea.Batch(cmds...)
+}
+
+// treeToggleAt expands or collapses the directory under the cursor.
+func (m *model) treeToggleAt() tea.Cmd {
+    pos := m.files.index()
+    if pos >= m.files.len() { return nil }
+    i := m.files.view[pos]
+    if m.files.store.flags[i]&entryOpen != 0 {
+        m.files.store.flags[i] &^= entryOpen
+        m.files.refreshTree()
+        return nil
+    }
+    cmd := m.treeExpand(i)
+    m.files.refreshTree()
+    return cmd
+}
+
+// treeBack collapses the directory under the cursor or moves to its parent;
+// it reports false at the top level so Back can leave the directory.
+func (m *model) treeBack() bool {
+    pos := m.files.index()
+    if pos >= m.files.len() { return false }
+    i := m.files.view[pos]
+    if m.files.store.flags[i]&entryOpen != 0 {
+        m.files.store.flags[i] &^= entryOpen
+        m.files.refreshTree()
+        return true
+    }
+    p, ok := m.files.tree.parent[i]
+    if !ok { return false }
+    m.files.rebuildTree(p)
+    return true
+}
+
+func (m *model) treeExpandAll() tea.Cmd {
+    t := m.files.tree
+    t.expandAll = true
+    cmd := m.treeAutoExpand(t.roots)
+    m.files.refreshTree()
+    return cmd
+}
+
+// treeCollapseAll closes every directory; the cursor moves to the top-level
+// entry containing it.
+func (m *model) treeCollapseAll() {
+    t := m.files.tree
+    t.expandAll = false
+    keep := int32(-1)
+    if pos := m.files.index(); pos < m.files.len() { keep = m.files.view[pos] }
+    for keep >= 0 {
+        p, ok := t.parent[keep]
+        if !ok { break }
+        keep = p
+    }
+    for i := range m.files.store.flags { m.files.store.flags[i] &^= entryOpen }
+    m.files.rebuildTree(keep)
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "time"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// ---------- Undo journal ----------
+
+// journalOp is one completed mutation. Kinds: move (also renames), copy,
+// link, chmod (Mode before, NewMode after), chown (Owner) and trash (To is
+// the entry in the Trash, empty when it is not known).
+type journalOp struct {
+    Kind     string `json:"kind"`
+    From     string `json:"from"`
+    To       string `json:"to,omitempty"`
+    Replaced bool   `json:"replaced,omitempty"` // copy merged into or overwrote an existing entry
+    Mode     uint32 `json:"mode,omitempty"`
+    NewMode  uint32 `json:"new_mode,omitempty"`
+    Owner    *ownerChange `json:"owner,omitempty"`
+}
+
+// modeBits is the part of a file mode chmod changes.
+func modeBits(fi fs.FileInfo) uint32 {
+    return uint32(fi.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky))
+}
+
+// journalBatch groups the ops of one confirmed action, so one undo reverts
+// a whole rename or copy.
+type journalBatch struct {
+    ID  int64       `json:"id"`
+    Act string      `json:"act"`
+    At  int64       `json:"at"`
+    Ops []journalOp `json:"ops"`
+}
+
+// undoJournal keeps the undo and redo stacks in undo.json under the state
+// dir, rewritten atomically after every change so they survive restarts.
+// A batch being undone or redone sits in Pending until its job reports
+// back, so quitting or crashing mid-job does not drop it.
+type undoJournal struct {
+    path    string
+    lastID  int64
+    Undo    []journalBatch `json:"undo"`
+    Redo    []journalBatch `json:"redo"`
+    Pending []pendingBatch `json:"pending,omitempty"`
+}
+
+// pendingBatch is a batch taken off a stack (Redo tells which) by a job
+// that has not finished.
+type pendingBatch struct {
+    Redo  bool         `json:"redo"`
+    Batch journalBatch `json:"batch"`
+}
+
+const maxUndoBatches = 100
+
+var errNoUndo = errors.New("cannot be undone")
+
+// journalActs names the actions that are journalled.
+var journalActs = map[action]string{actMoveToDir: "move", actRenamePattern: "rename", actEditRename: "rename", actCopyTo: "copy", actLinkTo: "symlink", actChmod: "chmod", actChown: "chown", actTrash: "trash"}
+
+func loadUndoJournal() *undoJournal {
+    j := &undoJournal{}
+    if dir := stateDir(); dir != "" { j.path = filepath.Join(dir, "undo.json") }
+    if j.path == "" { return j }
+    if b, err := os.ReadFile(j.path); err == nil { _ = json.Unmarshal(b, j) }
+    // a job that never reported back: the whole batch goes back on its
+    // stack; ops it had already run fail there and stop a retry, not lose it
+    for _, p := range j.Pending { j.push(p.Redo, p.Batch) }
+    j.Pending = nil
+    return j
+}
+
+func (j *undoJournal) save() {
+    if j.path == "" { return }
+    b, err := json.MarshalIndent(j, "", "  ")
+    if err != nil { return }
+    if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil { return }
+    _ = writeFileAtomic(j.path, b)
+}
+
+// newBatch returns an id for the ops of one action; ids stay unique across
+// restarts.
+func (j *undoJournal) newBatch() int64 {
+    id := time.Now().UnixNano()
+    if id <= j.lastID { id = j.lastID + 1 }
+    j.lastID = id
+    return id
+}
+
+// record adds a completed op to its batch, starting the batch (and
+// dropping the redo stack) on its first op.
+func (j *undoJournal) record(id int64, act string, op journalOp) {
+    op.From = absDir(op.From)
+    if op.To != "" { op.To = absDir(op.To) }
+    for k := len(j.Undo) - 1; k >= 0 && k >= len(j.Undo)-8; k-- {
+        if j.Undo[k].ID == id {
+            j.Undo[k].Ops = append(j.Undo[k].Ops, op)
+            j.save()
+            return
+        }
+    }
+    j.Undo = append(j.Undo, journalBatch{ID: id, Act: act, At: time.Now().Unix(), Ops: []journalOp{op}})
+    if len(j.Undo) > maxUndoBatches { j.Undo = j.Undo[len(j.Undo)-maxUndoBatches:] }
+    j.Redo = nil
+    j.save()
+}
+
+// pop takes the latest batch off the undo (or redo) stack.
+func (j *undoJournal) pop(redo bool) (journalBatch, bool) {
+    st := &j.Undo
+    if redo { st = &j.Redo }
+    if len(*st) == 0 { return journalBatch{}, false }
+    b := (*st)[len(*st)-1]
+    *st = (*st)[:len(*st)-1]
+    return b, true
+}
+
+func (j *undoJournal) push(redo bool, b journalBatch) {
+    if redo { j.Redo = append(j.Redo, b) } else { j.Undo = append(j.Undo, b) }
+}
+
+// settle drops the pending entry of a finished undo or redo job.
+func (j *undoJournal) settle(redo bool, id int64) {
+    for k, p := range j.Pending {
+        if p.Redo == redo && p.Batch.ID == id { j.Pending = append(j.Pending[:k], j.Pending[k+1:]...); return }
+    }
+}
+
+// peek describes the batch the next undo or redo would apply to.
+func (j *undoJournal) peek(redo bool) string {
+    st := j.Undo
+    if redo { st = j.Redo }
+    if len(st) == 0 { return "" }
+    b := st[len(st)-1]
+    return fmt.Sprintf("%s, %d item(s)", b.Act, len(b.Ops))
+}
+
+// revert undoes op; errNoUndo marks kinds that cannot be reverted.
+func (o journalOp) revert(ctx context.Context) error {
+    switch {
+    case o.Replaced: return errNoUndo
+    case o.Kind == "move":
+        if _, err := os.Lstat(o.From); err == nil { return fmt.Errorf("%s: exists again", o.From) }
+        _, err := moveEntry(ctx, o.To, o.From)
+        return err
+    case o.Kind == "copy":
+        _, err := moveToTrash(ctx, o.To)
+        return err
+    case o.Kind == "chmod":
+        return os.Chmod(o.From, fs.FileMode(o.Mode))
+    case o.Kind == "chown" && o.Owner != nil:
+        return os.Lchown(o.From, o.Owner.UID, o.Owner.GID)
+    case o.Kind == "trash" && o.To != "":
+        return restoreFromTrash(ctx, o.To, o.From)
+    case o.Kind == "link":
+        fi, err := os.Lstat(o.To)
+        if err != nil { return err }
+        if fi.Mode()&os.ModeSymlink == 0 { return fmt.Errorf("%s: no longer a symlink", o.To) }
+        return os.Remove(o.To)
+    }
+    return errNoUndo
+}
+
+// apply redoes op after it was reverted; a trash op gets its new location.
+func (o journalOp) apply(ctx context.Context) (journalOp, error) {
+    switch {
+    case o.Replaced: return o, errNoUndo
+    case o.Kind == "chmod": return o, os.Chmod(o.From, fs.FileMode(o.NewMode))
+    case o.Kind == "chown" && o.Owner != nil: return o, os.Lchown(o.From, o.Owner.NewUID, o.Owner.NewGID)
+    case o.Kind == "trash":
+        if o.To == "" { return o, errNoUndo }
+        loc, err := moveToTrash(ctx, o.From)
+        if err == nil && loc == "" { err = fmt.Errorf("%s: trashed, but its place in the Trash is unknown", o.From) }
+        o.To = loc
+        return o, err
+    }
+    if _, err := os.Lstat(o.To); err == nil { return o, fmt.Errorf("%s: exists again", o.To) }
+    switch o.Kind {
+    case "move":
+        _, err := moveEntry(ctx, o.From, o.To)
+        return o, err
+    case "copy":
+        c := &copier{ctx: ctx, p: &copyProgress{}}
+        return o, c.copy(o.From, o.To)
+    case "link":
+        return o, symlinkEntry(o.From, o.To)
+    }
+    return o, errNoUndo
+}
+
+// ---------- Model glue ----------
+
+type undoDoneMsg struct {
+    redo    bool
+    done    journalBatch // ops reverted (or reapplied), in their original order
+    left    journalBatch // ops not reached because of a failure
+    skipped int          // ops of kinds that cannot be reverted
+}
+
+// runUndo reverts the latest batch, or with redo reapplies the latest
+// undone one, as a job. Ops run in reverse order for undo and stop at the
+// first failure or cancellation; what was not reached goes back on its
+// stack, all of it when the job is cancelled before it starts. Until then
+// the batch is saved as pending.
+func (m *model) runUndo(redo bool) tea.Cmd {
+    b, ok := m.journal.pop(redo)
+    if !ok {
+        if redo { m.status = "nothing to redo" } else { m.status = "nothing to undo" }
+        return nil
+    }
+    m.journal.Pending = append(m.journal.Pending, pendingBatch{Redo: redo, Batch: b})
+    m.journal.save()
+    verb := "undo"
+    if redo { verb = "redo" }
+    j := m.jobs.add(verb, fmt.Sprintf("%s, %d item(s)", b.Act, len(b.Ops)), func(ctx context.Context, j *job) (tea.Msg, error) {
+        msg := undoDoneMsg{redo: redo, done: b, left: b}
+        msg.done.Ops, msg.left.Ops = nil, nil
+        n := len(b.Ops)
+        j.total.Store(int64(n))
+        var err error
+        for k := 0; k < n; k++ {
+            i := n - 1 - k
+            if redo { i = k }
+            op := b.Ops[i]
+            if err = ctx.Err(); err == nil {
+                if redo { op, err = op.apply(ctx) } else { err = op.revert(ctx) }
+            }
+            if errors.Is(err, errNoUndo) { msg.skipped++; err = nil }
+            if err != nil {
+                if redo { msg.left.Ops = b.Ops[i:] } else { msg.left.Ops = b.Ops[:i+1] }
+                break
+            }
+            msg.done.Ops = append(msg.done.Ops, op)
+            j.n.Add(1)
+        }
+        if !redo {
+            for x, y := 0, len(msg.done.Ops)-1; x < y; x, y = x+1, y-1 { msg.done.Ops[x], msg.done.Ops[y] = msg.done.Ops[y], msg.done.Ops[x] }
+        }
+        return msg, err
+    })
+    j.unrun = func() tea.Msg {
+        msg := undoDoneMsg{redo: redo, done: b, left: b}
+        msg.done.Ops = nil
+        return msg
+    }
+    // what is left of the batch is back on its stack: retrying takes it
+    // from there rather than rerunning the whole batch
+    j.again = func(m *model) tea.Cmd { return m.retryUndo(redo, b.ID) }
+    return m.jobs.run(j)
+}
+
+// retryUndo continues an undo or redo that stopped part-way, provided the
+// rest of its batch is still next on the stack.
+func (m *model) retryUndo(redo bool, id int64) tea.Cmd {
+    st := m.journal.Undo
+    if redo { st = m.journal.Redo }
+    if len(st) == 0 || st[len(st)-1].ID != id { m.status = "that batch is no longer next in the journal"; return nil }
+    return m.runUndo(redo)
+}
+
+func (m *model) applyUndoDone(msg undoDoneMsg) tea.Cmd {
+    m.journal.settle(msg.redo, msg.done.ID)
+    if len(msg.done.Ops) > 0 { m.journal.push(!msg.redo, msg.done) }
+    if len(msg.left.Ops) > 0 { m.journal.push(msg.redo, msg.left) }
+    m.journal.save()
+    verb := "undid"
+    if msg.redo { verb = "redid" }
+    s := fmt.Sprintf("%s %s (%d item(s))", verb, msg.done.Act, len(msg.done.Ops)-msg.skipped)
+    if msg.skipped > 0 { s += fmt.Sprintf(", %d cannot be undone", msg.skipped) }
+    m.status = s
+    reload := m.reloadList()
+    return tea.Batch(reload, m.refreshPeer())
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+
+    tea "github.com/charmbracelet/bubbletea"
+)
+
+// undoTestModel is a model over dir with an empty journal in a temporary
+// state directory.
+func undoTestModel(t *testing.T, dir string) model {
+    t.Helper()
+    t.Setenv("XDG_STATE_HOME", t.TempDir())
+    m := initialModelFromArgs([]string{dir})
+    t.Cleanup(m.cancelScan)
+    return m
+}
+
+// runJob runs cmd (a queued job) and feeds its end message to the model.
+func runJob(t *testing.T, m model, cmd tea.Cmd) model {
+    t.Helper()
+    if cmd == nil { t.Fatal("no job was queued") }
+    end, ok := cmd().(jobEndMsg)
+    if !ok { t.Fatal("job did not end with a jobEndMsg") }
+    tm, _ := m.Update(end)
+    return tm.(model)
+}
+
+func exists(p string) bool { _, err := os.Lstat(p); return err == nil }
+
+func TestUndoCancelledWhileQueued(t *testing.T) {
+    dir := t.TempDir()
+    os.WriteFile(filepath.Join(dir, "b"), nil, 0o644)
+    m := undoTestModel(t, dir)
+    m.journal.record(m.journal.newBatch(), "move", journalOp{Kind: "move", From: filepath.Join(dir, "a"), To: filepath.Join(dir, "b")})
+
+    m.jobs.sem = make(chan struct{}, 1)
+    m.jobs.sem <- struct{}{} // the only worker is busy
+    cmd := m.runUndo(false)
+    if len(m.journal.Undo) != 0 { t.Fatalf("batch not taken off the stack") }
+    j := m.jobs.list[len(m.jobs.list)-1]
+    j.cancel()
+    m = runJob(t, m, cmd)
+    if j.state != jobCancelled { t.Errorf("job state = %v, want cancelled", j.state) }
+    if len(m.journal.Undo) != 1 || len(m.journal.Undo[0].Ops) != 1 { t.Fatalf("undo stack = %+v, want the batch back", m.journal.Undo) }
+    if exists(filepath.Join(dir, "a")) { t.Fatal("cancelled undo ran") }
+
+    <-m.jobs.sem
+    m = runJob(t, m, m.retryJob(j))
+    if !exists(filepath.Join(dir, "a")) || exists(filepath.Join(dir, "b")) { t.Errorf("retry did not undo the move") }
+    if len(m.journal.Undo) != 0 || len(m.journal.Redo) != 1 { t.Errorf("undo %d / redo %d batches, want 0 / 1", len(m.journal.Undo), len(m.journal.Redo)) }
+}
+
+func TestUndoRetryCoversTheRest(t *testing.T) {
+    dir := t.TempDir()
+    p := func(s string) string { return filepath.Join(dir, s) }
+    os.WriteFile(p("y1"), nil, 0o644)
+    os.WriteFile(p("y2"), nil, 0o644)
+    m := undoTestModel(t, dir)
+    id := m.journal.newBatch()
+    m.journal.record(id, "move", journalOp{Kind: "move", From: p("x1"), To: p("y1")})
+    m.journal.record(id, "move", journalOp{Kind: "move", From: p("x2"), To: p("y2")})
+
+    // undo runs y2→x2 first, then y1→x1 fails because x1 is back
+    os.WriteFile(p("x1"), nil, 0o644)
+    m = runJob(t, m, m.runUndo(false))
+    j := m.jobs.list[len(m.jobs.list)-1]
+    if j.state != jobFailed { t.Fatalf("job state = %v, want failed", j.state) }
+    if !exists(p("x2")) { t.Fatal("first op was not undone") }
+    if len(m.journal.Undo) != 1 || len(m.journal.Undo[0].Ops) != 1 || m.journal.Undo[0].Ops[0].To != p("y1") {
+        t.Fatalf("undo stack = %+v, want just the failed op", m.journal.Undo)
+    }
+
+    os.Remove(p("x1"))
+    m = runJob(t, m, m.retryJob(j))
+    if r := m.jobs.list[len(m.jobs.list)-1]; r.state != jobDone { t.Fatalf("retry: %v %v", r.state, r.err) }
+    if !exists(p("x1")) || exists(p("y1")) { t.Errorf("retry did not undo the rest") }
+    if len(m.journal.Undo) != 0 { t.Errorf("undo stack = %+v, want empty", m.journal.Undo) }
+
+    // once the batch is gone a stale retry does nothing
+    if cmd := m.retryJob(j); cmd != nil { t.Errorf("stale retry queued a job") }
+}
+
+func TestUndoInterruptedSurvivesRestart(t *testing.T) {
+    dir := t.TempDir()
+    os.WriteFile(filepath.Join(dir, "b"), nil, 0o644)
+    m := undoTestModel(t, dir)
+    m.journal.record(m.journal.newBatch(), "move", journalOp{Kind: "move", From: filepath.Join(dir, "a"), To: filepath.Join(dir, "b")})
+
+    // the job is queued but the program quits before it reports back
+    cmd := m.runUndo(false)
+    if j := loadUndoJournal(); len(j.Undo) != 1 || len(j.Pending) != 0 {
+        t.Fatalf("after restart: undo %d / pending %d batches, want 1 / 0", len(j.Undo), len(j.Pending))
+    }
+
+    m = runJob(t, m, cmd)
+    j := loadUndoJournal()
+    if len(j.Undo) != 0 || len(j.Redo) != 1 || len(j.Pending) != 0 {
+        t.Errorf("after undo: undo %d / redo %d / pending %d batches, want 0 / 1 / 0", len(j.Undo), len(j.Redo), len(j.Pending))
+    }
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/charmbracelet/bubbles/list"
+    "github.com/charmbracelet/lipgloss"
+    "github.com/mattn/go-runewidth"
+)
+
+// ---------- Virtualized file list ----------
+
+const (
+    entryDir uint8 = 1 << iota
+    entrySel
+    entryStat        // meta[i] is filled
+    entryStatPending // a stat pass for i is in flight
+    entryOpen        // tree view: the directory is expanded
+)
+
+// fileStore packs the entries of a listing: all paths share one byte arena
+// and per-entry data lives in parallel slices, so a million entries cost a
+// few tens of MB and no per-entry allocations.
+type fileStore struct {
+    arena []byte
+    ends  []int    // path i is arena[ends[i-1]:ends[i]]
+    names []uint16 // basename length, the tail of the path
+    flags []uint8
+    depth []uint8 // tree view nesting level
+    meta  []entryMeta // stat data, allocated on first use; see entryStat
+}
+
+func (s *fileStore) len() int { return len(s.ends) }
+
+func (s *fileStore) pathBytes(i int) []byte {
+    start := 0
+    if i > 0 { start = s.ends[i-1] }
+    return s.arena[start:s.ends[i]]
+}
+
+func (s *fileStore) path(i int) string { return string(s.pathBytes(i)) }
+
+func (s *fileStore) nameBytes(i int) []byte {
+    p := s.pathBytes(i)
+    return p[len(p)-int(s.names[i]):]
+}
+
+func (s *fileStore) add(it fileItem) {
+    s.arena = append(s.arena, it.path...)
+    s.ends = append(s.ends, len(s.arena))
+    n := len(it.path) - strings.LastIndexByte(it.path, '/') - 1
+    if n == 0 || n > 0xffff { n = len(it.path) & 0xffff }
+    s.names = append(s.names, uint16(n))
+    var f uint8
+    if it.isDir { f |= entryDir }
+    if it.selected { f |= entrySel }
+    s.flags = append(s.flags, f)
+    d := it.depth
+    if d > 0xff { d = 0xff }
+    s.depth = append(s.depth, uint8(d))
+}
+
+func (s *fileStore) metaAt(i int) (entryMeta, bool) {
+    if s.flags[i]&entryStat == 0 { return entryMeta{}, false }
+    return s.meta[i], true
+}
+
+func (s *fileStore) setMeta(i int, em entryMeta) {
+    if n := len(s.ends); len(s.meta) < n { s.meta = append(s.meta, make([]entryMeta, n-len(s.meta))...) }
+    s.meta[i] = em
+    s.flags[i] = s.flags[i]&^entryStatPending | entryStat
+}
+
+// missingMeta lists the entries that have not been stat'ed yet.
+func (s *fileStore) missingMeta() []int32 {
+    var idx []int32
+    for i, f := range s.flags { if f&entryStat == 0 { idx = append(idx, int32(i)) } }
+    return idx
+}
+
+func (s *fileStore) item(i int) fileItem {
+    return fileItem{path: s.path(i), isDir: s.flags[i]&entryDir != 0, selected: s.flags[i]&entrySel != 0, depth: int(s.depth[i])}
+}
+
+// vlist renders only the visible window of a fileStore. order holds store
+// indices in display order; view is order narrowed by the filter query, and
+// cursor/offset index into view.
+type vlist struct {
+    title  string
+    store  fileStore
+    order  []int32
+    view   []int32
+    query  string
+    sortInfo string // shown in the title bar
+    cursor int
+    offset int
+    nsel   int
+    width  int
+    height int
+    states map[string]previewState // preview state notes, shared with the model
+    detail bool // one row per entry with ls -l style columns
+    cols   detailConfig
+    git    gitStatus
+    tree   *treeState // non-nil in tree view
+    styles list.DefaultItemStyles
+    titleStyle lipgloss.Style
+}
+
+const vlistRowHeight = 3 // title, description, gap (like the default delegate)
+
+func newVList(states map[string]previewState) vlist {
+    return vlist{
+        title: "Files",
+        states: states,
+        styles: list.NewDefaultItemStyles(),
+        titleStyle: lipgloss.NewStyle().Background(lipgloss.Color("62")).Foreground(lipgloss.Color("230")).Padding(0, 1),
+    }
+}
+
+func (l *vlist) setSize(w, h int) { l.width, l.height = w, h; l.scroll() }
+
+// perPage is the number of items that fit below the title bar (and the
+// column header in the detail view).
+func (l *vlist) perPage() int {
+    n := (l.height - 2 + 1) / vlistRowHeight
+    if l.detail { n = l.height - 3 }
+    if n < 1 { n = 1 }
+    return n
+}
+
+func (l *vlist) reset() {
+    if l.tree != nil { l.tree.cancel(); l.tree = nil }
+    l.store = fileStore{}
+    l.order, l.view = nil, nil
+    l.git = gitStatus{}
+    l.cursor, l.offset, l.nsel = 0, 0, 0
+}
+
+func (l *vlist) setItems(items []fileItem) { l.reset(); l.append(items) }
+
+// append adds entries at the end of the display order.
+func (l *vlist) append(items []fileItem) {
+    for _, it := range items {
+        i := int32(l.store.len())
+        l.store.add(it)
+        l.order = append(l.order, i)
+        if l.tree != nil { l.tree.roots = append(l.tree.roots, i) }
+        if it.selected { l.nsel++ }
+        if l.matches(i) { l.view = append(l.view, i) }
+    }
+}
+
+func (l *vlist) len() int   { return len(l.view) }
+func (l *vlist) total() int { return l.store.len() }
+func (l *vlist) index() int { return l.cursor }
+
+func (l *vlist) itemAt(pos int) fileItem { return l.store.item(int(l.view[pos])) }
+
+func (l *vlist) selectedItem() (fileItem, bool) {
+    if l.cursor < 0 || l.cursor >= len(l.view) { return fileItem{}, false }
+    return l.itemAt(l.cursor), true
+}
+
+// ---------- Cursor ----------
+
+func (l *vlist) selectPos(pos int) {
+    if pos >= len(l.view) { pos = len(l.view) - 1 }
+    if pos < 0 { pos = 0 }
+    l.cursor = pos
+    l.scroll()
+}
+
+func (l *vlist) move(delta int) { l.selectPos(l.cursor + delta) }
+func (l *vlist) pageUp()        { l.move(-l.perPage()) }
+func (l *vlist) pageDown()      { l.move(l.perPage()) }
+func (l *vlist) top()           { l.selectPos(0) }
+func (l *vlist) bottom()        { l.selectPos(len(l.view) - 1) }
+
+// scroll keeps the cursor inside the visible window.
+func (l *vlist) scroll() {
+    per := l.perPage()
+    if l.cursor < l.offset { l.offset = l.cursor }
+    if l.cursor >= l.offset+per { l.offset = l.cursor - per + 1 }
+    if max := len(l.view) - per; l.offset > max { l.offset = max }
+    if l.offset < 0 { l.offset = 0 }
+}
+
+// find returns the view position of path p, or -1.
+func (l *vlist) find(p string) int {
+    for pos, i := range l.view {
+        if string(l.store.pathBytes(int(i))) == p { return pos }
+    }
+    return -1
+}
+
+// ---------- Selection ----------
+
+func (l *vlist) toggle(pos int) {
+    if pos < 0 || pos >= len(l.view) { return }
+    i := l.view[pos]
+    l.store.flags[i] ^= entrySel
+    if l.store.flags[i]&entrySel != 0 { l.nsel++ } else { l.nsel-- }
+}
+
+// selectAll marks every entry matching the filter.
+func (l *vlist) selectAll() {
+    for _, i := range l.view {
+        if l.store.flags[i]&entrySel == 0 { l.store.flags[i] |= entrySel; l.nsel++ }
+    }
+}
+
+func (l *vlist) clearSelection() {
+    for i := range l.store.flags { l.store.flags[i] &^= entrySel }
+    l.nsel = 0
+}
+
+// selectedItems lists marked entries in display order; in the tree view
+// marks inside collapsed directories count too.
+func (l *vlist) selectedItems() []fileItem {
+    if l.nsel == 0 { return nil }
+    out := make([]fileItem, 0, l.nsel)
+    if l.tree != nil {
+        l.tree.walk(&l.store, func(i int32) { if l.store.flags[i]&entrySel != 0 { out = append(out, l.store.item(int(i))) } }, false)
+        return out
+    }
+    for _, i := range l.order {
+        if l.store.flags[i]&entrySel != 0 { out = append(out, l.store.item(int(i))) }
+    }
+    return out
+}
+
+// ---------- Filtering and ordering ----------
+
+// setFilter narrows the view to names containing q (case-insensitive); a q
+// with a slash matches against the whole path. The cursor stays on the same
+// entry when it survives the filter.
+func (l *vlist) setFilter(q string) {
+    keep := int32(-1)
+    if l.cursor < len(l.view) { keep = l.view[l.cursor] }
+    l.query = q
+    l.rebuildView(keep)
+}
+
+func (l *vlist) rebuildView(keep int32) {
+    if l.query == "" {
+        l.view = append(l.view[:0], l.order...)
+    } else {
+        l.view = l.view[:0]
+        for _, i := range l.order { if l.matches(i) { l.view = append(l.view, i) } }
+    }
+    pos := 0
+    if keep >= 0 {
+        for p, i := range l.view { if i == keep { pos = p; break } }
+    }
+    l.offset = 0
+    l.selectPos(pos)
+}
+
+func (l *vlist) matches(i int32) bool {
+    if l.query == "" { return true }
+    hay := l.store.nameBytes(int(i))
+    if strings.IndexByte(l.query, '/') >= 0 { hay = l.store.pathBytes(int(i)) }
+    return containsFold(hay, l.query)
+}
+
+func (l *vlist) sortBy(less func(a, b int32) bool) {
+    keep := int32(-1)
+    if l.cursor < len(l.view) { keep = l.view[l.cursor] }
+    if l.tree != nil {
+        // siblings are sorted among themselves
+        sortIDs := func(ids []int32) { sort.SliceStable(ids, func(x, y int) bool { return less(ids[x], ids[y]) }) }
+        sortIDs(l.tree.roots)
+        for _, ids := range l.tree.kids { sortIDs(ids) }
+        l.rebuildTree(keep)
+        return
+    }
+    sort.SliceStable(l.order, func(x, y int) bool { return less(l.order[x], l.order[y]) })
+    l.rebuildView(keep)
+}
+
+// containsFold reports whether hay contains q ignoring case, without
+// allocating for ASCII names.
+func containsFold(hay []byte, q string) bool {
+    if len(q) > len(hay) { return false }
+    for _, c := range hay {
+        if c >= utf8.RuneSelf { return strings.Contains(strings.ToLower(string(hay)), strings.ToLower(q)) }
+    }
+    for i := 0; i+len(q) <= len(hay); i++ {
+        j := 0
+        for ; j < len(q); j++ {
+            if lowerASCII(hay[i+j]) != lowerASCII(q[j]) { break }
+        }
+        if j == len(q) { return true }
+    }
+    return false
+}
+
+func lowerASCII(c byte) byte {
+    if 'A' <= c && c <= 'Z' { return c + 'a' - 'A' }
+    return c
+}
+
+// foldCmp compares names like strings.Compare(strings.ToLower(a),
+// strings.ToLower(b)) without allocating.
+func foldCmp(a, b []byte) int {
+    for len(a) > 0 && len(b) > 0 {
+        if a[0] < utf8.RuneSelf && b[0] < utf8.RuneSelf {
+            ca, cb := lowerASCII(a[0]), lowerASCII(b[0])
+            if ca != cb { return int(ca) - int(cb) }
+            a, b = a[1:], b[1:]
+            continue
+        }
+        ra, na := utf8.DecodeRune(a)
+        rb, nb := utf8.DecodeRune(b)
+        ra, rb = unicode.ToLower(ra), unicode.ToLower(rb)
+        if ra != rb { return int(ra) - int(rb) }
+        a, b = a[na:], b[nb:]
+    }
+    return len(a) - len(b)
+}
+
+// ---------- Rendering ----------
+
+// View renders the title bar and the visible window only; its cost does not
+// depend on the number of entries.
+func (l *vlist) View() string {
+    info := fmt.Sprintf("%d items", len(l.view))
+    if l.query != "" { info = fmt.Sprintf("%d/%d match %q", len(l.view), l.store.len(), l.query) }
+    if l.sortInfo != "" { info += " · " + l.sortInfo }
+    if len(l.view) > l.perPage() { info += fmt.Sprintf(" · %d%%", (l.cursor+1)*100/len(l.view)) }
+    lines := make([]string, 0, l.height)
+    lines = append(lines, "  "+l.titleStyle.Render(l.title)+" "+l.styles.DimmedDesc.Render(info), "")
+    tw := l.width - 2
+    if tw < 1 { tw = 1 }
+    var cols []detailColumn
+    nameW, now := 0, time.Now()
+    if l.detail {
+        cols, nameW = l.cols.fit(tw - 2)
+        lines = append(lines, l.styles.DimmedTitle.Render(l.detailRow(-1, cols, nameW, now)))
+    }
+    if len(l.view) == 0 {
+        msg := "No items."
+        if l.query != "" { msg = "No matches." }
+        lines = append(lines, l.styles.DimmedTitle.Render(msg))
+    }
+    end := l.offset + l.perPage()
+    if end > len(l.view) { end = len(l.view) }
+    if l.detail {
+        for pos := l.offset; pos < end; pos++ {
+            row := l.detailRow(int(l.view[pos]), cols, nameW, now)
+            if pos == l.cursor { row = l.styles.SelectedTitle.Render(row) } else { row = l.styles.NormalTitle.Render(row) }
+            lines = append(lines, row)
+        }
+    } else {
+        for pos := l.offset; pos < end; pos++ {
+            it := l.itemAt(pos)
+            if st := l.states[it.path]; st != previewIdle { it.note = st.String() }
+            title := it.Title()
+            if l.tree != nil { title = l.treeLabel(int(l.view[pos]), true) }
+            title = runewidth.Truncate(title, tw, "…")
+            desc := runewidth.Truncate(it.Description(), tw, "…")
+            if pos == l.cursor {
+                title, desc = l.styles.SelectedTitle.Render(title), l.styles.SelectedDesc.Render(desc)
+            } else {
+                title, desc = l.styles.NormalTitle.Render(title), l.styles.NormalDesc.Render(desc)
+            }
+            if pos > l.offset { lines = append(lines, "") }
+            lines = append(lines, title, desc)
+        }
+    }
+    for len(lines) < l.height { lines = append(lines, "") }
+    if l.height > 0 && len(lines) > l.height { lines = lines[:l.height] }
+    return lipgloss.NewStyle().Width(l.width).Render(strings.Join(lines, "\n"))
+}
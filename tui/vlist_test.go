@@ -0,0 +1,76 @@
+package main
+
+import (
+    "fmt"
+    "testing"
+)
+
+// Benchmarks for the virtualized list at one million entries:
+//
+//   go test -run '^$' -bench VList -benchmem ./...
+
+const benchEntries = 1_000_000
+
+func benchList(b *testing.B) *vlist {
+    b.Helper()
+    items := make([]fileItem, 0, 4096)
+    l := newVList(map[string]previewState{})
+    l.setSize(60, 40)
+    for i := 0; i < benchEntries; i++ {
+        items = append(items, fileItem{path: fmt.Sprintf("/data/big/file-%07d.txt", benchEntries-i), isDir: i%97 == 0})
+        if len(items) == cap(items) { l.append(items); items = items[:0] }
+    }
+    l.append(items)
+    return &l
+}
+
+func BenchmarkVListAppend1M(b *testing.B) {
+    for i := 0; i < b.N; i++ { benchList(b) }
+}
+
+func BenchmarkVListView1M(b *testing.B) {
+    l := benchList(b)
+    l.selectPos(benchEntries / 2)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ { _ = l.View() }
+}
+
+// BenchmarkVListScroll1M is one keypress: move the cursor and re-render.
+func BenchmarkVListScroll1M(b *testing.B) {
+    l := benchList(b)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        l.move(1)
+        _ = l.View()
+    }
+}
+
+func BenchmarkVListFilter1M(b *testing.B) {
+    l := benchList(b)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        l.setFilter("file-00042")
+        l.setFilter("")
+    }
+}
+
+func BenchmarkVListSort1M(b *testing.B) {
+    l := benchList(b)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        b.StopTimer()
+        for j := range l.order { l.order[j] = int32(len(l.order) - 1 - j) }
+        b.StartTimer()
+        l.sortBy(sortSpec{}.less(&l.store, false))
+    }
+}
+
+func BenchmarkVListSelectAll1M(b *testing.B) {
+    l := benchList(b)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        l.selectAll()
+        _ = l.selectedItems()
+        l.clearSelection()
+    }
+}
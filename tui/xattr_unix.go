@@ -0,0 +1,27 @@
+//go:build linux || darwin
+
+package main
+
+import (
+    "bytes"
+
+    "golang.org/x/sys/unix"
+)
+
+// copyXattrs copies extended attributes from one path to another where the
+// filesystems allow it; failures are ignored, like cp -p does.
+func copyXattrs(from, to string) {
+    size, err := unix.Llistxattr(from, nil)
+    if err != nil || size <= 0 { return }
+    names := make([]byte, size)
+    if size, err = unix.Llistxattr(from, names); err != nil { return }
+    for _, name := range bytes.Split(names[:size], []byte{0}) {
+        if len(name) == 0 { continue }
+        attr := string(name)
+        n, err := unix.Lgetxattr(from, attr, nil)
+        if err != nil { continue }
+        val := make([]byte, n)
+        if n, err = unix.Lgetxattr(from, attr, val); err != nil { continue }
+        _ = unix.Lsetxattr(to, attr, val[:n], 0)
+    }
+}